@@ -37,16 +37,30 @@ export const AuthProvider = ({ children }) => {
     }
   }
 
+  const startSession = async ({ token, refresh_token }) => {
+    localStorage.setItem('token', token)
+    localStorage.setItem('refresh_token', refresh_token)
+    setToken(token)
+    api.defaults.headers.common['Authorization'] = `Bearer ${token}`
+
+    await fetchUser()
+  }
+
   const login = async (email, password) => {
     try {
       const response = await api.post('/user/login', { email, password })
-      const { token } = response.data.data
-      
-      localStorage.setItem('token', token)
-      setToken(token)
-      api.defaults.headers.common['Authorization'] = `Bearer ${token}`
-      
-      await fetchUser()
+      const data = response.data.data
+
+      if (data.mfa_required) {
+        return {
+          success: false,
+          mfaRequired: true,
+          mfaToken: data.mfa_token,
+          setup: data.setup
+        }
+      }
+
+      await startSession(data)
       return { success: true }
     } catch (error) {
       return { 
@@ -56,6 +70,21 @@ export const AuthProvider = ({ children }) => {
     }
   }
 
+  const verifyMfa = async (mfaToken, code) => {
+    try {
+      const response = await api.post('/user/login/2fa', { mfa_token: mfaToken, code })
+      const data = response.data.data
+
+      await startSession(data)
+      return { success: true, recoveryCodes: data.recovery_codes }
+    } catch (error) {
+      return {
+        success: false,
+        error: error.response?.data?.error || 'Verification failed'
+      }
+    }
+  }
+
   const register = async (userData) => {
     try {
       const response = await api.post('/user/register', userData)
@@ -77,6 +106,7 @@ export const AuthProvider = ({ children }) => {
       console.error('Logout error:', error)
     } finally {
       localStorage.removeItem('token')
+      localStorage.removeItem('refresh_token')
       setToken(null)
       setUser(null)
       delete api.defaults.headers.common['Authorization']
@@ -99,6 +129,7 @@ export const AuthProvider = ({ children }) => {
   const value = {
     user,
     login,
+    verifyMfa,
     register,
     logout,
     updateProfile,
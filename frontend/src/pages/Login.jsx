@@ -14,7 +14,9 @@ const Login = () => {
   const [loading, setLoading] = useState(false)
   const [showPassword, setShowPassword] = useState(false)
   const [showChangePassword, setShowChangePassword] = useState(false)
-  const { login } = useAuth()
+  const [mfa, setMfa] = useState(null)
+  const [mfaCode, setMfaCode] = useState('')
+  const { login, verifyMfa } = useAuth()
   const navigate = useNavigate()
   const location = useLocation()
 
@@ -77,6 +79,8 @@ const Login = () => {
 
     if (result.success) {
       navigate('/dashboard')
+    } else if (result.mfaRequired) {
+      setMfa({ token: result.mfaToken, setup: result.setup })
     } else {
       const errorPayload = result.error
       let errorMessage = 'Login failed'
@@ -97,6 +101,32 @@ const Login = () => {
     setLoading(false)
   }
 
+  const handleMfaSubmit = async (e) => {
+    e.preventDefault()
+    setError('')
+
+    if (!mfaCode.trim()) {
+      setError('Authentication code is required')
+      return
+    }
+
+    setLoading(true)
+
+    const result = await verifyMfa(mfa.token, mfaCode.trim())
+
+    if (result.success) {
+      if (result.recoveryCodes?.length) {
+        window.alert(`Store these recovery codes somewhere safe:\n\n${result.recoveryCodes.join('\n')}`)
+      }
+      navigate('/dashboard')
+    } else {
+      const errorPayload = result.error
+      setError(errorPayload?.message || String(errorPayload))
+    }
+
+    setLoading(false)
+  }
+
   return (
     <div className="login-container">
       <Container>
@@ -127,6 +157,42 @@ const Login = () => {
                   </div>
                 )}
 
+                {mfa ? (
+                <Form onSubmit={handleMfaSubmit}>
+                  {mfa.setup && (
+                    <Alert variant="info">
+                      Two-factor authentication is required for your account. Add this key to your
+                      authenticator app, then enter the generated code:
+                      <div className="mt-2"><code>{mfa.setup.secret}</code></div>
+                    </Alert>
+                  )}
+                  <Form.Group className="mb-4">
+                    <Form.Label>Authentication code</Form.Label>
+                    <Form.Control
+                      type="text"
+                      name="code"
+                      value={mfaCode}
+                      onChange={(e) => setMfaCode(e.target.value)}
+                      placeholder="6-digit code or recovery code"
+                      autoComplete="one-time-code"
+                      autoFocus
+                    />
+                  </Form.Group>
+
+                  <Button
+                    type="submit"
+                    variant="primary"
+                    size="lg"
+                    className="w-100 mb-3"
+                    disabled={loading}
+                  >
+                    {loading ? 'Verifying...' : 'Verify'}
+                  </Button>
+                  <Button variant="link" className="w-100 mb-3" onClick={() => { setMfa(null); setMfaCode('') }}>
+                    Back to sign in
+                  </Button>
+                </Form>
+                ) : (
                 <Form onSubmit={handleSubmit}>
                   <Form.Group className="mb-3">
                     <Form.Label>Email</Form.Label>
@@ -183,6 +249,7 @@ const Login = () => {
                     )}
                   </Button>
                 </Form>
+                )}
 
                 <div className="text-center">
                   <p className="mb-0">
@@ -23,12 +23,47 @@ api.interceptors.request.use(
   }
 )
 
+let refreshRequest = null
+
+// Exchange the stored refresh token for a new token pair; concurrent 401s share one request.
+const refreshTokens = () => {
+  if (!refreshRequest) {
+    const refreshToken = localStorage.getItem('refresh_token')
+    refreshRequest = axios
+      .post(`${API_BASE_URL}/user/refresh`, { refresh_token: refreshToken })
+      .then((response) => {
+        const { token, refresh_token } = response.data.data
+        localStorage.setItem('token', token)
+        localStorage.setItem('refresh_token', refresh_token)
+        api.defaults.headers.common['Authorization'] = `Bearer ${token}`
+        return token
+      })
+      .finally(() => {
+        refreshRequest = null
+      })
+  }
+  return refreshRequest
+}
+
 // Response interceptor
 api.interceptors.response.use(
   (response) => response,
-  (error) => {
+  async (error) => {
+    const original = error.config
+    if (error.response?.status === 401 && original && !original._retry && localStorage.getItem('refresh_token')) {
+      original._retry = true
+      try {
+        const token = await refreshTokens()
+        original.headers.Authorization = `Bearer ${token}`
+        return api(original)
+      } catch (refreshError) {
+        // fall through to logout
+      }
+    }
+
     if (error.response?.status === 401) {
       localStorage.removeItem('token')
+      localStorage.removeItem('refresh_token')
       window.location.href = '/login'
     }
     return Promise.reject(error)
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"workshop-management/pkg/logger"
+	"workshop-management/utils"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func ConnRedis() (*redis.Client, error) {
+	addr := fmt.Sprintf("%s:%s", utils.GetEnv("REDIS_HOST", "localhost").(string), utils.GetEnv("REDIS_PORT", "6379").(string))
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("ConnRedis; Initialize redis connection to %s...", addr))
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: utils.GetEnv("REDIS_PASS", "").(string),
+		DB:       utils.GetEnv("REDIS_DB", 0).(int),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("ConnRedis; %s Error: %s", addr, err.Error()))
+		_ = client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"workshop-management/internal/domain/auth"
+	"workshop-management/internal/dto"
+	authRepo "workshop-management/internal/repositories/auth"
+	userRepo "workshop-management/internal/repositories/user"
+	userSvc "workshop-management/internal/services/user"
+	"workshop-management/pkg/logger"
+	"workshop-management/pkg/mailer"
+	"workshop-management/pkg/sms"
+	"workshop-management/utils"
+
+	"gorm.io/gorm"
+)
+
+const adminUsage = `Usage: workshop-management admin create --email <email> [--name <name>] [--phone <phone>] [--password <password>]
+
+Creates an admin account, or promotes the existing account with that email to admin.
+When --password is omitted, ADMIN_PASSWORD is used or a random password is generated and printed.
+`
+
+func newUserService(db *gorm.DB, blacklist auth.RepoAuth) *userSvc.ServiceUser {
+	return userSvc.NewUserService(
+		userRepo.NewUserRepo(db),
+		blacklist,
+		authRepo.NewSessionRepo(db),
+		authRepo.NewPasswordResetRepo(db),
+		authRepo.NewMemoryLoginAttemptRepo(),
+		mailer.NewMailer(),
+		authRepo.NewVerificationRepo(db),
+		sms.NewSender(),
+		authRepo.NewMfaRepo(db),
+	)
+}
+
+// RunAdmin executes the `admin` subcommand with the arguments following it. blacklist must be the token
+// blacklist the API servers read, so logins revoked by a promotion are rejected everywhere.
+func RunAdmin(db *gorm.DB, blacklist auth.RepoAuth, args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] != "create" {
+		fmt.Fprint(out, adminUsage)
+		return errors.New("unknown admin command")
+	}
+
+	fs := flag.NewFlagSet("admin create", flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.Usage = func() { fmt.Fprint(out, adminUsage) }
+
+	var req dto.BootstrapAdmin
+	fs.StringVar(&req.Email, "email", "", "admin email (required)")
+	fs.StringVar(&req.Name, "name", "Administrator", "admin name, used when creating the account")
+	fs.StringVar(&req.Phone, "phone", "", "admin phone, required when creating the account")
+	fs.StringVar(&req.Password, "password", os.Getenv("ADMIN_PASSWORD"), "admin password, used when creating the account")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if req.Email == "" {
+		fs.Usage()
+		return errors.New("--email is required")
+	}
+
+	generated := false
+	if req.Password == "" {
+		password, err := generatePassword()
+		if err != nil {
+			return err
+		}
+		req.Password, generated = password, true
+	}
+
+	data, created, err := newUserService(db, blacklist).EnsureAdmin(req, true)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case created && generated:
+		fmt.Fprintf(out, "Admin %s created with password: %s\n", data.Email, req.Password)
+	case created:
+		fmt.Fprintf(out, "Admin %s created\n", data.Email)
+	default:
+		fmt.Fprintf(out, "User %s is an admin\n", data.Email)
+	}
+	return nil
+}
+
+// SeedAdmin creates the admin described by ADMIN_SEED_EMAIL, ADMIN_SEED_PASSWORD, ADMIN_SEED_NAME and
+// ADMIN_SEED_PHONE when no account with that email exists. Existing accounts are never modified, so it
+// is safe to run on every start.
+func SeedAdmin(db *gorm.DB, blacklist auth.RepoAuth) error {
+	email := utils.GetEnv("ADMIN_SEED_EMAIL", "").(string)
+	if email == "" {
+		return nil
+	}
+
+	req := dto.BootstrapAdmin{
+		Email:    email,
+		Name:     utils.GetEnv("ADMIN_SEED_NAME", "Administrator").(string),
+		Phone:    utils.GetEnv("ADMIN_SEED_PHONE", "").(string),
+		Password: utils.GetEnv("ADMIN_SEED_PASSWORD", "").(string),
+	}
+
+	data, created, err := newUserService(db, blacklist).EnsureAdmin(req, false)
+	if err != nil {
+		return err
+	}
+	if created {
+		logger.WriteLog(logger.LogLevelInfo, fmt.Sprintf("SeedAdmin; admin %s created", data.Email))
+	}
+	return nil
+}
+
+func generatePassword() (string, error) {
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
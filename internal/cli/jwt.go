@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+	"workshop-management/pkg/jwks"
+	"workshop-management/utils"
+)
+
+const jwtUsage = `Usage: workshop-management jwt keygen [--alg RS256|EdDSA] [--dir <dir>] [--kid <kid>]
+
+Writes a new private signing key to <dir>/<kid>.pem. The kid defaults to the current UTC time, so the
+newest key signs new tokens while older keys keep verifying the tokens they issued.
+`
+
+// RunJwt executes the `jwt` subcommand with the arguments following it.
+func RunJwt(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] != "keygen" {
+		fmt.Fprint(out, jwtUsage)
+		return errors.New("unknown jwt command")
+	}
+
+	fs := flag.NewFlagSet("jwt keygen", flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.Usage = func() { fmt.Fprint(out, jwtUsage) }
+
+	var alg, dir, kid string
+	fs.StringVar(&alg, "alg", "EdDSA", "signing algorithm, RS256 or EdDSA")
+	fs.StringVar(&dir, "dir", utils.GetEnv("JWT_KEYS_DIR", "keys").(string), "key directory")
+	fs.StringVar(&kid, "kid", time.Now().UTC().Format("2006-01-02T150405"), "key identifier, used as the file name")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if kid == "" || filepath.Base(kid) != kid {
+		return errors.New("--kid must be a plain file name")
+	}
+
+	key, err := jwks.GenerateKey(alg, rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, kid+".pem")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err = file.Write(key); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%s key %s written to %s\n", alg, kid, path)
+	return nil
+}
@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"workshop-management/utils"
+)
+
+var ErrInvalidTime = errors.New("from and to must use the YYYY-MM-DD or RFC 3339 format")
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Entities maps each audited table to the entity name recorded in its events.
+var Entities = map[string]string{
+	"users":               "user",
+	"vehicles":            "vehicle",
+	"services":            "service",
+	"bookings":            "booking",
+	"work_orders":         "work_order",
+	"work_order_services": "work_order_service",
+	"work_order_parts":    "work_order_part",
+}
+
+func (Event) TableName() string {
+	return "audit_events"
+}
+
+// Event is one create, update or delete of an audited row. Before and After only hold the columns that
+// changed, except for creates (After is the whole row) and hard deletes (Before is the whole row).
+type Event struct {
+	Id        string          `json:"id" gorm:"column:id;primaryKey"`
+	LogId     string          `json:"log_id,omitempty" gorm:"column:log_id"`
+	ActorId   string          `json:"actor_id,omitempty" gorm:"column:actor_id"`
+	ActorRole string          `json:"actor_role,omitempty" gorm:"column:actor_role"`
+	Entity    string          `json:"entity" gorm:"column:entity"`
+	EntityId  string          `json:"entity_id" gorm:"column:entity_id"`
+	Action    string          `json:"action" gorm:"column:action"`
+	Before    json.RawMessage `json:"before,omitempty" gorm:"column:before;type:jsonb"`
+	After     json.RawMessage `json:"after,omitempty" gorm:"column:after;type:jsonb"`
+	CreatedAt time.Time       `json:"created_at" gorm:"column:created_at"`
+}
+
+// Actor identifies who caused a change and the request it belongs to.
+type Actor struct {
+	UserId string
+	Role   string
+	LogId  string
+}
+
+// ActorFrom reads the actor from a request context (the gin context passed to WithContext), using the
+// log id and auth data set by the middlewares. Contexts without them, e.g. background jobs, return an
+// empty actor.
+func ActorFrom(ctx context.Context) Actor {
+	var actor Actor
+	if ctx == nil {
+		return actor
+	}
+
+	if logId := ctx.Value(utils.CtxKeyId); logId != nil {
+		actor.LogId = fmt.Sprint(logId)
+	}
+	if authData, ok := ctx.Value(utils.CtxKeyAuthData).(map[string]interface{}); ok {
+		actor.UserId = utils.InterfaceString(authData["user_id"])
+		actor.Role = utils.InterfaceString(authData["role"])
+	}
+
+	return actor
+}
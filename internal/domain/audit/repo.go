@@ -0,0 +1,7 @@
+package audit
+
+import "workshop-management/pkg/filter"
+
+type RepoAudit interface {
+	Fetch(params filter.BaseParams) ([]Event, int64, error)
+}
@@ -1,13 +1,142 @@
 package auth
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidResetToken   = errors.New("reset token is invalid or has expired")
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or has expired")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used; the session has been revoked")
+
+	ErrInvalidVerificationCode = errors.New("verification code is invalid or has expired")
+	ErrVerificationTooSoon     = errors.New("a verification code was sent recently, please wait before requesting another one")
+	ErrAlreadyVerified         = errors.New("account is already verified")
+
+	ErrInvalidMfaToken    = errors.New("two-factor login has expired, please log in again")
+	ErrInvalidMfaCode     = errors.New("invalid two-factor code")
+	ErrMfaAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrMfaNotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrMfaSetupNotStarted = errors.New("two-factor setup has not been started")
+	ErrMfaRequiredForRole = errors.New("two-factor authentication is mandatory for your role")
+)
+
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+)
 
 func (Blacklist) TableName() string {
 	return "blacklist"
 }
 
+// Blacklist holds logged-out tokens until they expire, keyed by the JWT id (jti).
 type Blacklist struct {
 	ID        string    `gorm:"primaryKey" json:"id"`
+	Jti       string    `json:"jti"`
 	Token     string    `gorm:"not null; unique" json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+func (Revocation) TableName() string {
+	return "token_revocations"
+}
+
+// Revocation invalidates every token of a user issued before RevokedAt.
+type Revocation struct {
+	UserId    string    `gorm:"primaryKey" json:"user_id"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+func (PasswordReset) TableName() string {
+	return "password_resets"
+}
+
+// PasswordReset is a single-use reset token. Only the SHA-256 hash of the token is stored.
+type PasswordReset struct {
+	Id        string     `json:"id"`
+	UserId    string     `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// Session is one login of a user. It is the family of all refresh tokens rotated from that login,
+// and revoking it invalidates both the refresh tokens and the access tokens carrying its id (sid).
+type Session struct {
+	Id         string     `json:"id"`
+	UserId     string     `json:"user_id"`
+	UserAgent  string     `json:"user_agent"`
+	IpAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"-"`
+	Current    bool       `json:"current" gorm:"-"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// RefreshToken is single-use: refreshing marks it as used and issues the next token of the session.
+type RefreshToken struct {
+	Id        string
+	SessionId string
+	TokenHash string
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (VerificationCode) TableName() string {
+	return "verification_codes"
+}
+
+// VerificationCode is a one-time code proving the user controls Target, the email or phone number
+// it was sent to over Channel. Only a hash of the code is stored.
+type VerificationCode struct {
+	Id        string     `gorm:"primaryKey" json:"id"`
+	UserId    string     `json:"user_id"`
+	Channel   string     `json:"channel"`
+	Target    string     `json:"target"`
+	CodeHash  string     `json:"-"`
+	Attempts  int        `json:"attempts"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
+
+// RecoveryCode is a single-use code that replaces the TOTP code when the authenticator is lost.
+type RecoveryCode struct {
+	Id        string     `gorm:"primaryKey" json:"id"`
+	UserId    string     `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (MfaChallenge) TableName() string {
+	return "mfa_challenges"
+}
+
+// MfaChallenge is issued after a correct password for users with two-factor authentication. The
+// second login step exchanges it, together with a valid code, for the session tokens.
+type MfaChallenge struct {
+	Id        string     `gorm:"primaryKey" json:"id"`
+	UserId    string     `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
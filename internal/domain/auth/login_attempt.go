@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrLoginLocked = errors.New("too many failed login attempts, please try again later")
+
+// LoginAttempt is the failed-login state of one counter key (an account or a client IP).
+type LoginAttempt struct {
+	Failures    int
+	LockedUntil time.Time
+}
+
+func (a LoginAttempt) IsLocked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
+
+// LockoutPolicy locks a key once it reaches MaxAttempts failures. The lock starts at BaseLockout and
+// doubles with every further failure up to MaxLockout. Failures are forgotten Window after the last
+// failure (or lock).
+type LockoutPolicy struct {
+	MaxAttempts int
+	Window      time.Duration
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+}
+
+// LockFor returns how long a key with the given number of failures stays locked, 0 when it is not.
+func (p LockoutPolicy) LockFor(failures int) time.Duration {
+	if p.MaxAttempts <= 0 || failures < p.MaxAttempts {
+		return 0
+	}
+
+	lock := p.BaseLockout
+	for i := p.MaxAttempts; i < failures && lock < p.MaxLockout; i++ {
+		lock *= 2
+	}
+	return min(lock, p.MaxLockout)
+}
+
+// LockedError is returned while a login counter is locked; it matches ErrLoginLocked.
+type LockedError struct {
+	Until time.Time
+}
+
+func (e *LockedError) Error() string {
+	return ErrLoginLocked.Error()
+}
+
+func (e *LockedError) Is(target error) bool {
+	return target == ErrLoginLocked
+}
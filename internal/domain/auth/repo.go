@@ -1,6 +1,51 @@
 package auth
 
+import "time"
+
 type RepoAuth interface {
 	Store(m Blacklist) error
-	GetByToken(token string) (Blacklist, error)
+	IsBlacklisted(jti string) (bool, error)
+	RevokeUser(userId string, at time.Time) error
+	GetRevokedAt(userId string) (time.Time, error)
+	IsSessionRevoked(sessionId string, now time.Time) (bool, error)
+	RevokeSessions(sessions []Session) error
+}
+
+type RepoPasswordReset interface {
+	Store(m PasswordReset) error
+	Consume(tokenHash string, now time.Time) (PasswordReset, error)
+}
+
+type RepoVerification interface {
+	Store(m VerificationCode) error
+	GetLatest(userId string) (VerificationCode, error)
+	Consume(userId, codeHash string, maxAttempts int, now time.Time) (VerificationCode, error)
+}
+
+type RepoMfa interface {
+	StoreChallenge(m MfaChallenge) error
+	GetChallenge(tokenHash string, now time.Time) (MfaChallenge, error)
+	ConsumeChallenge(id string, now time.Time) error
+	ReplaceRecoveryCodes(userId string, codes []RecoveryCode) error
+	ConsumeRecoveryCode(userId, codeHash string, now time.Time) (bool, error)
+}
+
+type RepoSession interface {
+	Create(session Session, token RefreshToken) error
+	Rotate(tokenHash string, next RefreshToken, now time.Time) (Session, error)
+	GetActiveByUserId(userId string, now time.Time) ([]Session, error)
+	Revoke(userId, sessionId string, now time.Time) ([]Session, error)
+	RevokeAll(userId, exceptSessionId string, now time.Time) ([]Session, error)
+}
+
+// RepoCleanup removes rows that are no longer needed once their expiry has passed.
+type RepoCleanup interface {
+	PruneExpired(now time.Time) (int64, error)
+}
+
+// RepoLoginAttempt counts failed logins per key and tracks the resulting lockouts.
+type RepoLoginAttempt interface {
+	Get(key string, now time.Time) (LoginAttempt, error)
+	RegisterFailure(key string, policy LockoutPolicy, now time.Time) (LoginAttempt, error)
+	Reset(key string) error
 }
@@ -1,6 +1,8 @@
 package booking
 
 import (
+	"errors"
+	"fmt"
 	"time"
 	"workshop-management/internal/domain/service"
 	"workshop-management/internal/domain/vehicle"
@@ -8,6 +10,34 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	ErrUnverifiedCustomer = errors.New("please verify your email or phone number before making a booking")
+	ErrNotOwner           = errors.New("only the customer who made the booking can change it")
+	ErrNotEditable        = errors.New("only pending bookings can be changed")
+	ErrStatusChanged      = errors.New("booking status was changed by someone else, reload and try again")
+	ErrUnknownService     = errors.New("one or more services do not exist")
+	ErrVehicleNotFound    = errors.New("vehicle not found")
+)
+
+// ServiceError explains why one of the requested service ids cannot be booked
+type ServiceError struct {
+	ServiceId string `json:"service_id"`
+	Message   string `json:"message"`
+}
+
+// InvalidServicesError lists every requested service id that cannot be booked. It matches ErrUnknownService.
+type InvalidServicesError struct {
+	Services []ServiceError
+}
+
+func (e *InvalidServicesError) Error() string {
+	return fmt.Sprintf("%d of the requested services cannot be booked", len(e.Services))
+}
+
+func (e *InvalidServicesError) Unwrap() error {
+	return ErrUnknownService
+}
+
 func (b *Booking) TableName() string {
 	return "bookings"
 }
@@ -29,6 +59,7 @@ type Booking struct {
 
 	Services []service.Service `json:"services,omitempty" gorm:"many2many:booking_services;"`
 	Vehicle  vehicle.Vehicle   `gorm:"foreignKey:VehicleId"`
+	Quote    *Quote            `json:"quote,omitempty" gorm:"foreignKey:BookingId"`
 }
 
 // BookService entity (join table)
@@ -41,3 +72,18 @@ type BookService struct {
 func (bs *BookService) TableName() string {
 	return "booking_services"
 }
+
+// StatusHistory is one status transition of a booking
+type StatusHistory struct {
+	Id         string    `json:"id"`
+	BookingId  string    `json:"booking_id"`
+	FromStatus *string   `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	ChangedAt  time.Time `json:"changed_at"`
+	ChangedBy  string    `json:"changed_by"`
+}
+
+func (sh *StatusHistory) TableName() string {
+	return "booking_status_history"
+}
@@ -0,0 +1,134 @@
+package booking
+
+import (
+	"errors"
+	"math"
+	"time"
+	"workshop-management/internal/domain/service"
+	"workshop-management/utils"
+)
+
+func (Quote) TableName() string {
+	return "booking_quotes"
+}
+
+func (QuoteItem) TableName() string {
+	return "booking_quote_items"
+}
+
+// PriceRules are the discount and tax rules applied to estimates and invoices.
+type PriceRules struct {
+	TaxRate             float64 // percent of the discounted subtotal
+	DiscountRate        float64 // percent off the services subtotal
+	DiscountMinServices int     // number of services a booking needs for the discount
+}
+
+// LoadPriceRules reads the pricing rules from the environment:
+//
+//	TAX_RATE (0), SERVICE_DISCOUNT_RATE (0), SERVICE_DISCOUNT_MIN_SERVICES (3)
+func LoadPriceRules() (PriceRules, error) {
+	r := PriceRules{
+		TaxRate:             utils.GetEnv("TAX_RATE", 0.0).(float64),
+		DiscountRate:        utils.GetEnv("SERVICE_DISCOUNT_RATE", 0.0).(float64),
+		DiscountMinServices: utils.GetEnv("SERVICE_DISCOUNT_MIN_SERVICES", 3).(int),
+	}
+
+	if r.TaxRate < 0 || r.TaxRate > 100 || r.DiscountRate < 0 || r.DiscountRate > 100 || r.DiscountMinServices < 1 {
+		return PriceRules{}, errors.New("invalid pricing configuration")
+	}
+
+	return r, nil
+}
+
+// Discount returns the discount on a services subtotal made up of serviceCount services.
+func (r PriceRules) Discount(subtotal float64, serviceCount int) float64 {
+	if serviceCount < r.DiscountMinServices {
+		return 0
+	}
+	return roundPrice(subtotal * r.DiscountRate / 100)
+}
+
+// Quote prices services at their current catalog price.
+func (r PriceRules) Quote(services []service.Service) Quote {
+	q := Quote{TaxRate: r.TaxRate, Items: make([]QuoteItem, 0, len(services))}
+	for _, svc := range services {
+		q.Items = append(q.Items, QuoteItem{
+			ServiceId:   svc.Id,
+			ServiceName: svc.Name,
+			UnitPrice:   svc.Price,
+			Quantity:    1,
+			Subtotal:    roundPrice(svc.Price),
+		})
+		q.Subtotal += roundPrice(svc.Price)
+	}
+
+	q.Subtotal = roundPrice(q.Subtotal)
+	q.Discount = r.Discount(q.Subtotal, len(services))
+	q.Tax = roundPrice((q.Subtotal - q.Discount) * q.TaxRate / 100)
+	q.Total = roundPrice(q.Subtotal - q.Discount + q.Tax)
+
+	return q
+}
+
+// Quote is a priced estimate of a booking. Once accepted it is stored with the booking, and the work
+// order and invoice use its prices instead of the current catalog.
+type Quote struct {
+	Id         string     `json:"id,omitempty"`
+	BookingId  string     `json:"booking_id,omitempty"`
+	VehicleId  string     `json:"vehicle_id"`
+	Subtotal   float64    `json:"subtotal"`
+	Discount   float64    `json:"discount"`
+	TaxRate    float64    `json:"tax_rate"`
+	Tax        float64    `json:"tax"`
+	Total      float64    `json:"total"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	AcceptedBy string     `json:"accepted_by,omitempty"`
+
+	Items []QuoteItem `json:"items" gorm:"foreignKey:QuoteId"`
+}
+
+// QuoteItem is one priced service of a quote
+type QuoteItem struct {
+	Id          string  `json:"id,omitempty"`
+	QuoteId     string  `json:"quote_id,omitempty"`
+	ServiceId   string  `json:"service_id"`
+	ServiceName string  `json:"service_name"`
+	UnitPrice   float64 `json:"unit_price"`
+	Quantity    int     `json:"quantity"`
+	Subtotal    float64 `json:"subtotal"`
+}
+
+// UnitPrice returns the quoted price of a service, or false when the service is not part of the quote.
+func (q *Quote) UnitPrice(serviceId string) (float64, bool) {
+	if q == nil {
+		return 0, false
+	}
+	for _, item := range q.Items {
+		if item.ServiceId == serviceId {
+			return item.UnitPrice, true
+		}
+	}
+	return 0, false
+}
+
+// roundPrice rounds an amount to cents.
+func roundPrice(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// DiscountFor returns the part of the quoted discount that belongs to the given services, in proportion to
+// their quoted subtotal. Quoted services that were skipped or removed do not keep their share.
+func (q Quote) DiscountFor(serviceIds map[string]bool) float64 {
+	if q.Discount <= 0 || q.Subtotal <= 0 {
+		return 0
+	}
+
+	var covered float64
+	for _, item := range q.Items {
+		if serviceIds[item.ServiceId] {
+			covered += item.Subtotal
+		}
+	}
+
+	return roundPrice(q.Discount * math.Min(covered/q.Subtotal, 1))
+}
@@ -0,0 +1,36 @@
+package booking
+
+import "testing"
+
+func TestQuoteDiscountFor(t *testing.T) {
+	quote := Quote{
+		Subtotal: 400,
+		Discount: 40,
+		Items: []QuoteItem{
+			{ServiceId: "s1", Subtotal: 100},
+			{ServiceId: "s2", Subtotal: 100},
+			{ServiceId: "s3", Subtotal: 200},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		quote    Quote
+		services map[string]bool
+		want     float64
+	}{
+		{"all quoted services", quote, map[string]bool{"s1": true, "s2": true, "s3": true}, 40},
+		{"one service skipped", quote, map[string]bool{"s1": true, "s3": true}, 30},
+		{"extra unquoted service", quote, map[string]bool{"s1": true, "s9": true}, 10},
+		{"no quoted service left", quote, map[string]bool{"s9": true}, 0},
+		{"quote without discount", Quote{Subtotal: 100, Items: []QuoteItem{{ServiceId: "s1", Subtotal: 100}}}, map[string]bool{"s1": true}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.quote.DiscountFor(tt.services); got != tt.want {
+				t.Fatalf("DiscountFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,16 +1,26 @@
 package booking
 
 import (
+	"context"
+	"time"
 	"workshop-management/internal/domain/service"
 	"workshop-management/pkg/filter"
 )
 
 type RepoBooking interface {
-	Create(booking Booking, bookingServices []BookService) error
+	WithContext(ctx context.Context) RepoBooking
+
+	Create(booking Booking, bookingServices []BookService, slot Slot, history StatusHistory) error
+	CountActiveBetween(start, end time.Time) (int64, error)
 	GetServicesByIDs(serviceIDs []string) ([]service.Service, error)
 	GetById(id string) (Booking, error)
 	GetByIdUserId(id, userId string) (Booking, error)
 	GetBookingServicesByBookingId(bookingId string) ([]BookService, error)
 	Fetch(params filter.BaseParams) ([]Booking, int64, error)
 	Update(m Booking, data interface{}) (int64, error)
+	UpdateDetails(m Booking, data map[string]interface{}, serviceIDs []string, slot *Slot) error
+	UpdateStatus(m Booking, data map[string]interface{}, history StatusHistory) (int64, error)
+	GetStatusHistory(bookingId string) ([]StatusHistory, error)
+	SaveQuote(quote Quote) error
+	GetQuote(bookingId string) (Quote, error)
 }
@@ -0,0 +1,108 @@
+package booking
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	_ "time/tzdata" // the runtime image ships without zoneinfo
+	"workshop-management/utils"
+)
+
+var (
+	ErrPastDate     = errors.New("booking date must be in the future")
+	ErrOutsideHours = errors.New("booking date is outside workshop opening hours")
+	ErrSlotFull     = errors.New("booking slot is fully booked")
+	ErrInvalidDate  = errors.New("date must use the YYYY-MM-DD format")
+)
+
+// Schedule describes when the workshop accepts bookings. Every day between Open and Close is split
+// into slots of SlotLength, and each slot can hold Capacity bookings (number of bays/mechanics).
+type Schedule struct {
+	Location   *time.Location
+	Open       time.Duration // offset from midnight
+	Close      time.Duration // offset from midnight
+	SlotLength time.Duration
+	Capacity   int
+}
+
+// Slot is a bookable time window.
+type Slot struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Capacity  int       `json:"capacity"`
+	Booked    int       `json:"booked"`
+	Available int       `json:"available"`
+}
+
+// LoadSchedule reads the schedule from the environment:
+//
+//	BOOKING_TIMEZONE (Asia/Jakarta), BOOKING_OPEN_TIME (08:00), BOOKING_CLOSE_TIME (17:00),
+//	BOOKING_SLOT_MINUTES (60), BOOKING_SLOT_CAPACITY (3)
+func LoadSchedule() (Schedule, error) {
+	loc, err := time.LoadLocation(utils.GetEnv("BOOKING_TIMEZONE", "Asia/Jakarta").(string))
+	if err != nil {
+		return Schedule{}, fmt.Errorf("invalid BOOKING_TIMEZONE: %w", err)
+	}
+
+	open, err := parseClock(utils.GetEnv("BOOKING_OPEN_TIME", "08:00").(string))
+	if err != nil {
+		return Schedule{}, fmt.Errorf("invalid BOOKING_OPEN_TIME: %w", err)
+	}
+
+	closeAt, err := parseClock(utils.GetEnv("BOOKING_CLOSE_TIME", "17:00").(string))
+	if err != nil {
+		return Schedule{}, fmt.Errorf("invalid BOOKING_CLOSE_TIME: %w", err)
+	}
+
+	s := Schedule{
+		Location:   loc,
+		Open:       open,
+		Close:      closeAt,
+		SlotLength: time.Duration(utils.GetEnv("BOOKING_SLOT_MINUTES", 60).(int)) * time.Minute,
+		Capacity:   utils.GetEnv("BOOKING_SLOT_CAPACITY", 3).(int),
+	}
+
+	if s.SlotLength <= 0 || s.Capacity <= 0 || s.Close-s.Open < s.SlotLength {
+		return Schedule{}, errors.New("invalid booking schedule configuration")
+	}
+
+	return s, nil
+}
+
+func parseClock(v string) (time.Duration, error) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Slots returns every slot of the given day (interpreted in the schedule's location), without counts.
+func (s Schedule) Slots(day time.Time) []Slot {
+	day = day.In(s.Location)
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, s.Location)
+
+	var slots []Slot
+	for start := s.Open; start+s.SlotLength <= s.Close; start += s.SlotLength {
+		slots = append(slots, Slot{
+			Start:    midnight.Add(start),
+			End:      midnight.Add(start + s.SlotLength),
+			Capacity: s.Capacity,
+		})
+	}
+	return slots
+}
+
+// SlotAt returns the slot containing t, or ErrPastDate / ErrOutsideHours when t cannot be booked.
+func (s Schedule) SlotAt(t, now time.Time) (Slot, error) {
+	if !t.After(now) {
+		return Slot{}, ErrPastDate
+	}
+
+	for _, slot := range s.Slots(t) {
+		if !t.Before(slot.Start) && t.Before(slot.End) {
+			return slot, nil
+		}
+	}
+	return Slot{}, ErrOutsideHours
+}
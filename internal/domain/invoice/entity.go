@@ -0,0 +1,58 @@
+package invoice
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+func (Item) TableName() string {
+	return "invoice_items"
+}
+
+const (
+	ItemTypeService = "service"
+	ItemTypePart    = "part"
+)
+
+var (
+	ErrAlreadyInvoiced = errors.New("work order already has an invoice")
+	ErrNotCompleted    = errors.New("invoice can only be generated for a completed work order")
+)
+
+type Invoice struct {
+	Id          string  `json:"id" gorm:"type:uuid;primaryKey"`
+	WorkOrderId string  `json:"work_order_id"`
+	Subtotal    float64 `json:"subtotal"`
+	Discount    float64 `json:"discount"`
+	Tax         float64 `json:"tax"`
+	Total       float64 `json:"total"`  // subtotal - discount + tax
+	Status      string  `json:"status"` // pending, partially_paid, paid, cancelled
+
+	CreatedAt time.Time      `json:"created_at"`
+	CreatedBy string         `json:"created_by"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	UpdatedBy string         `json:"updated_by"`
+	DeletedAt gorm.DeletedAt `json:"-"`
+	DeletedBy string         `json:"-"`
+
+	Items []Item `json:"items,omitempty" gorm:"foreignKey:InvoiceId"`
+}
+
+// Item is one invoice line, copied from a work order service or part at generation time
+type Item struct {
+	Id          string    `json:"id"`
+	InvoiceId   string    `json:"invoice_id"`
+	ItemType    string    `json:"item_type"` // service, part
+	ReferenceId string    `json:"reference_id"`
+	Description string    `json:"description"`
+	Quantity    int       `json:"quantity"`
+	UnitPrice   float64   `json:"unit_price"`
+	Subtotal    float64   `json:"subtotal"`
+	CreatedAt   time.Time `json:"created_at"`
+}
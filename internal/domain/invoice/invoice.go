@@ -1,12 +1,9 @@
 package invoice
 
-import "time"
+import "workshop-management/pkg/filter"
 
-type Invoice struct {
-	ID          string    `json:"id"`
-	WorkOrderID string    `json:"work_order_id"`
-	Total       float64   `json:"total"`
-	Status      string    `json:"status"` // pending, paid, cancelled
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+type Service interface {
+	CreateFromWorkOrder(workOrderId, userId string) (Invoice, error)
+	GetById(id string) (Invoice, error)
+	Fetch(params filter.BaseParams) ([]Invoice, int64, error)
 }
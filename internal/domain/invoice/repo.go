@@ -0,0 +1,11 @@
+package invoice
+
+import "workshop-management/pkg/filter"
+
+type RepoInvoice interface {
+	Create(m Invoice, items []Item) error
+	GetById(id string) (Invoice, error)
+	GetByWorkOrderId(workOrderId string) (Invoice, error)
+	Fetch(params filter.BaseParams) ([]Invoice, int64, error)
+	Update(m Invoice, data map[string]interface{}) (int64, error)
+}
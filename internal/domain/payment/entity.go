@@ -0,0 +1,66 @@
+package payment
+
+import (
+	"errors"
+	"math"
+	"time"
+	"workshop-management/utils"
+
+	"gorm.io/gorm"
+)
+
+func (Payment) TableName() string {
+	return "payments"
+}
+
+const (
+	MethodCash     = "cash"
+	MethodTransfer = "transfer"
+	MethodEWallet  = "e-wallet"
+)
+
+var (
+	ErrInvalidMethod = errors.New("payment method must be one of cash, transfer, e-wallet")
+	ErrInvalidAmount = errors.New("payment amount must be greater than 0")
+	ErrOverpayment   = errors.New("payment exceeds the outstanding invoice amount")
+	ErrInvoiceClosed = errors.New("invoice is already settled or cancelled")
+)
+
+type Payment struct {
+	Id        string    `json:"id" gorm:"type:uuid;primaryKey"`
+	InvoiceId string    `json:"invoice_id"`
+	Method    string    `json:"method"` // cash, transfer, e-wallet
+	Amount    float64   `json:"amount"`
+	PaidAt    time.Time `json:"paid_at"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	CreatedBy string         `json:"created_by"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	UpdatedBy string         `json:"updated_by"`
+	DeletedAt gorm.DeletedAt `json:"-"`
+	DeletedBy string         `json:"-"`
+}
+
+func ValidMethod(method string) bool {
+	switch method {
+	case MethodCash, MethodTransfer, MethodEWallet:
+		return true
+	}
+	return false
+}
+
+// InvoiceStatus derives the invoice status from its total and the sum of recorded payments.
+func InvoiceStatus(total, paid float64) string {
+	switch {
+	case Cents(paid) >= Cents(total):
+		return utils.StsPaid
+	case Cents(paid) > 0:
+		return utils.StsPartiallyPaid
+	}
+	return utils.StsPending
+}
+
+// Cents converts an amount to integer cents so that money is never compared as float.
+func Cents(v float64) int64 {
+	return int64(math.Round(v * 100))
+}
@@ -1,12 +1,12 @@
 package payment
 
-import "time"
+import (
+	"workshop-management/internal/domain/invoice"
+	"workshop-management/internal/dto"
+)
 
-type Payment struct {
-	ID        string    `json:"id"`
-	InvoiceID string    `json:"invoice_id"`
-	Method    string    `json:"method"` // cash, transfer, e-wallet
-	Amount    float64   `json:"amount"`
-	PaidAt    time.Time `json:"paid_at"`
-	CreatedAt time.Time `json:"created_at"`
+type Service interface {
+	Create(req dto.AddPayment, invoiceId, userId string) (Payment, invoice.Invoice, error)
+	GetById(id string) (Payment, error)
+	GetByInvoiceId(invoiceId string) ([]Payment, error)
 }
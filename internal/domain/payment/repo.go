@@ -0,0 +1,10 @@
+package payment
+
+import "workshop-management/internal/domain/invoice"
+
+type RepoPayment interface {
+	// Create stores the payment and moves the invoice status inside one transaction.
+	Create(m Payment) (invoice.Invoice, error)
+	GetById(id string) (Payment, error)
+	GetByInvoiceId(invoiceId string) ([]Payment, error)
+}
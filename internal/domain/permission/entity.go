@@ -0,0 +1,77 @@
+package permission
+
+import (
+	"errors"
+	"slices"
+	"time"
+	"workshop-management/utils"
+)
+
+var (
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrInvalidRole       = errors.New("role not found")
+	ErrImmutableRole     = errors.New("the admin role always has every permission")
+)
+
+const (
+	UserRead         = "user:read"
+	UserManage       = "user:manage"
+	VehicleWrite     = "vehicle:write"
+	ServiceWrite     = "service:write"
+	SparepartWrite   = "sparepart:write"
+	WorkOrderCreate  = "workorder:create"
+	WorkOrderAssign  = "workorder:assign"
+	WorkOrderUpdate  = "workorder:update"
+	WorkOrderParts   = "workorder:parts"
+	WorkOrderQueue   = "workorder:queue"
+	InvoiceRead      = "invoice:read"
+	InvoiceCreate    = "invoice:create"
+	PaymentRead      = "payment:read"
+	PaymentCreate    = "payment:create"
+	PermissionManage = "permission:manage"
+	AuditRead        = "audit:read"
+)
+
+type Definition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Registry lists every permission a route can require. Only registered permissions can be granted.
+var Registry = []Definition{
+	{UserRead, "View user accounts"},
+	{UserManage, "Create staff accounts, change roles, deactivate accounts and force password resets"},
+	{VehicleWrite, "Update and delete vehicles (customers are limited to their own)"},
+	{ServiceWrite, "Create, update and delete catalog services"},
+	{SparepartWrite, "Create, update and delete spare parts"},
+	{WorkOrderCreate, "Create work orders from bookings"},
+	{WorkOrderAssign, "Assign mechanics and view the assignment history"},
+	{WorkOrderUpdate, "Update work order status, notes and service lines"},
+	{WorkOrderParts, "Add, change and remove parts on work orders"},
+	{WorkOrderQueue, "View the work orders assigned to the caller"},
+	{InvoiceRead, "View invoices"},
+	{InvoiceCreate, "Generate invoices from work orders"},
+	{PaymentRead, "View payments"},
+	{PaymentCreate, "Record payments against invoices"},
+	{PermissionManage, "Edit the role to permission mapping"},
+	{AuditRead, "View the audit log of changes to users, vehicles, services, bookings and work orders"},
+}
+
+// EditableRoles are the roles whose permissions are stored in the database. Admin is not listed: it
+// always has every permission so the mapping can never lock administrators out.
+var EditableRoles = []string{utils.RoleCashier, utils.RoleMechanic, utils.RoleCustomer}
+
+func IsRegistered(name string) bool {
+	return slices.ContainsFunc(Registry, func(d Definition) bool { return d.Name == name })
+}
+
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
+
+type RolePermission struct {
+	Role       string    `json:"role" gorm:"primaryKey"`
+	Permission string    `json:"permission" gorm:"primaryKey"`
+	CreatedAt  time.Time `json:"created_at"`
+	CreatedBy  string    `json:"created_by"`
+}
@@ -0,0 +1,7 @@
+package permission
+
+type RepoPermission interface {
+	GetAll() ([]RolePermission, error)
+	HasPermission(role, permission string) (bool, error)
+	ReplaceRole(role string, permissions []string, userId string) error
+}
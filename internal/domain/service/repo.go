@@ -1,10 +1,13 @@
 package service
 
 import (
+	"context"
 	"workshop-management/pkg/filter"
 )
 
 type RepoService interface {
+	WithContext(ctx context.Context) RepoService
+
 	Store(m Service) error
 	Fetch(params filter.BaseParams) ([]Service, int64, error)
 	GetById(id string) (Service, error)
@@ -0,0 +1,26 @@
+package sparepart
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (Sparepart) TableName() string {
+	return "spareparts"
+}
+
+type Sparepart struct {
+	Id    string  `json:"id" gorm:"type:uuid;primaryKey"`
+	Code  string  `json:"code"`
+	Name  string  `json:"name"`
+	Stock int     `json:"stock"`
+	Price float64 `json:"price"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	CreatedBy string         `json:"created_by"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	UpdatedBy string         `json:"updated_by"`
+	DeletedAt gorm.DeletedAt `json:"-"`
+	DeletedBy string         `json:"-"`
+}
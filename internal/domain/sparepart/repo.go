@@ -0,0 +1,13 @@
+package sparepart
+
+import (
+	"workshop-management/pkg/filter"
+)
+
+type RepoSparepart interface {
+	Store(m Sparepart) error
+	Fetch(params filter.BaseParams) ([]Sparepart, int64, error)
+	GetById(id string) (Sparepart, error)
+	Update(m Sparepart, data interface{}) (int64, error)
+	Delete(m Sparepart, data interface{}) error
+}
@@ -1,13 +0,0 @@
-package sparepart
-
-import "time"
-
-type Sparepart struct {
-	ID        string    `json:"id"`
-	Code      string    `json:"code"`
-	Name      string    `json:"name"`
-	Stock     int       `json:"stock"`
-	Price     float64   `json:"price"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
@@ -1,11 +1,20 @@
 package user
 
 import (
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+var (
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrSelfModification = errors.New("you cannot change the role or status of your own account")
+	ErrAccountDisabled  = errors.New("account is deactivated")
+
+	ErrInvalidCredentials = errors.New("invalid email or password")
+)
+
 func (Users) TableName() string {
 	return "users"
 }
@@ -20,4 +29,27 @@ type Users struct {
 	CreatedAt time.Time      `json:"created_at,omitempty" gorm:"column:created_at"`
 	UpdatedAt *time.Time     `json:"updated_at,omitempty" gorm:"column:updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty" gorm:"column:deactivated_at"`
+	DeactivatedBy string     `json:"deactivated_by,omitempty" gorm:"column:deactivated_by"`
+	VerifiedAt    *time.Time `json:"verified_at,omitempty" gorm:"column:verified_at"`
+
+	TotpSecret    string     `json:"-" gorm:"column:totp_secret"`
+	TotpEnabledAt *time.Time `json:"totp_enabled_at,omitempty" gorm:"column:totp_enabled_at"`
+	TotpLastStep  int64      `json:"-" gorm:"column:totp_last_step"`
+}
+
+func (u Users) IsActive() bool {
+	return u.DeactivatedAt == nil
+}
+
+// HasTwoFactor reports whether the user completed TOTP enrollment. A secret without TotpEnabledAt is an
+// enrollment that has not been confirmed yet.
+func (u Users) HasTwoFactor() bool {
+	return u.TotpEnabledAt != nil
+}
+
+// IsVerified reports whether the user has confirmed their email or phone with a verification code.
+func (u Users) IsVerified() bool {
+	return u.VerifiedAt != nil
 }
@@ -1,14 +1,18 @@
 package user
 
 import (
+	"context"
 	"workshop-management/pkg/filter"
 )
 
 type RepoUser interface {
+	WithContext(ctx context.Context) RepoUser
+
 	Store(m Users) error
 	GetByEmail(email string) (Users, error)
 	GetByID(id string) (Users, error)
 	GetAll(params filter.BaseParams) ([]Users, int64, error)
 	Update(m Users) error
+	ClaimTotpStep(id string, step int64) (bool, error)
 	Delete(id string) error
 }
@@ -1,8 +1,13 @@
 package vehicle
 
-import "workshop-management/pkg/filter"
+import (
+	"context"
+	"workshop-management/pkg/filter"
+)
 
 type RepoVehicle interface {
+	WithContext(ctx context.Context) RepoVehicle
+
 	Store(m Vehicle) error
 	Fetch(params filter.BaseParams) ([]Vehicle, int64, error)
 	GetById(id string) (Vehicle, error)
@@ -1,7 +1,9 @@
 package workorder
 
 import (
+	"errors"
 	"time"
+	"workshop-management/internal/domain/sparepart"
 	"workshop-management/internal/domain/user"
 	"workshop-management/internal/domain/vehicle"
 
@@ -20,13 +22,21 @@ func (PartWorkOrder) TableName() string {
 	return "work_order_parts"
 }
 
+func (Assignment) TableName() string {
+	return "work_order_assignments"
+}
+
+func (StatusHistory) TableName() string {
+	return "work_order_status_history"
+}
+
 type WorkOrder struct {
 	Id         string  `json:"id" gorm:"type:uuid;primaryKey"`
 	BookingId  string  `json:"booking_id" gorm:"type:uuid;not null"`
 	CustomerId string  `json:"customer_id" gorm:"type:uuid;not null"`
 	VehicleId  string  `json:"vehicle_id" gorm:"type:uuid;not null"`
 	MechanicId *string `json:"mechanic_id"`
-	Status     string  `json:"status"` // pending, in_progress, completed
+	Status     string  `json:"status"` // open, on progress, waiting_parts, quality_check, completed, cancelled
 	Notes      string  `json:"notes"`
 
 	CreatedAt time.Time      `json:"created_at"`
@@ -42,13 +52,31 @@ type WorkOrder struct {
 	Parts    []PartWorkOrder `gorm:"foreignKey:WorkOrderId"`
 }
 
+var (
+	ErrInsufficientStock = errors.New("insufficient sparepart stock")
+	ErrNotEditable       = errors.New("work order can no longer be modified")
+	ErrNotAssigned       = errors.New("work order is not assigned to you")
+	ErrInvalidMechanic   = errors.New("assigned user is not a mechanic")
+	ErrAlreadyAssigned   = errors.New("mechanic is already assigned to this work order")
+	ErrMechanicBusy      = errors.New("mechanic has reached the maximum number of active work orders")
+	ErrNotAssignable     = errors.New("work order is closed and can no longer be assigned")
+)
+
 type PartWorkOrder struct {
-	Id          string    `json:"id"`
-	WorkOrderId string    `json:"work_order_id"`
-	SparepartId string    `json:"sparepart_id"`
-	Quantity    int       `json:"quantity"`
-	Price       float64   `json:"price"`
-	CreatedAt   time.Time `json:"created_at"`
+	Id          string  `json:"id"`
+	WorkOrderId string  `json:"work_order_id"`
+	SparepartId string  `json:"sparepart_id"`
+	Quantity    int     `json:"quantity"`
+	Price       float64 `json:"price"` // snapshot of spareparts.price when the line was added
+
+	CreatedAt time.Time      `json:"created_at"`
+	CreatedBy string         `json:"created_by"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	UpdatedBy string         `json:"updated_by"`
+	DeletedAt gorm.DeletedAt `json:"-"`
+	DeletedBy string         `json:"-"`
+
+	Sparepart sparepart.Sparepart `json:"sparepart" gorm:"foreignKey:SparepartId"`
 }
 
 type SvcWorkOrder struct {
@@ -58,7 +86,7 @@ type SvcWorkOrder struct {
 	ServiceName string  `json:"service_name"`
 	Price       float64 `json:"price"`
 	Quantity    int     `json:"quantity"`
-	Status      string  `json:"status"`
+	Status      string  `json:"status"` // open, started, done, skipped
 
 	CreatedAt time.Time `json:"created_at"`
 	CreatedBy string    `json:"created_by"`
@@ -67,3 +95,25 @@ type SvcWorkOrder struct {
 	DeletedAt time.Time `json:"-"`
 	DeletedBy string    `json:"-"`
 }
+
+// Assignment is the audit trail of mechanic (re)assignments of a work order
+type Assignment struct {
+	Id                 string    `json:"id"`
+	WorkOrderId        string    `json:"work_order_id"`
+	MechanicId         string    `json:"mechanic_id"`
+	PreviousMechanicId *string   `json:"previous_mechanic_id"`
+	Reason             string    `json:"reason"`
+	AssignedAt         time.Time `json:"assigned_at"`
+	AssignedBy         string    `json:"assigned_by"`
+}
+
+// StatusHistory is one status transition of a work order
+type StatusHistory struct {
+	Id          string    `json:"id"`
+	WorkOrderId string    `json:"work_order_id"`
+	FromStatus  *string   `json:"from_status"`
+	ToStatus    string    `json:"to_status"`
+	Reason      string    `json:"reason"`
+	ChangedAt   time.Time `json:"changed_at"`
+	ChangedBy   string    `json:"changed_by"`
+}
@@ -1,10 +1,29 @@
 package workorder
 
-import "workshop-management/pkg/filter"
+import (
+	"context"
+	"workshop-management/pkg/filter"
+)
 
 type RepoWorkOrder interface {
-	Create(workOrder WorkOrder, svcWorkOrders []SvcWorkOrder) error
+	WithContext(ctx context.Context) RepoWorkOrder
+
+	Create(workOrder WorkOrder, svcWorkOrders []SvcWorkOrder, history StatusHistory) error
 	GetById(id string) (WorkOrder, error)
 	Update(workOrder WorkOrder, data map[string]interface{}) (int64, error)
 	Fetch(params filter.BaseParams) ([]WorkOrder, int64, error)
+	UpdateStatus(workOrder WorkOrder, data map[string]interface{}, history StatusHistory) (int64, error)
+	Cancel(workOrder WorkOrder, data map[string]interface{}, history StatusHistory) (int64, error)
+	GetStatusHistory(workOrderId string) ([]StatusHistory, error)
+	Assign(workOrder WorkOrder, data map[string]interface{}, assignment Assignment, maxActive int) (int64, error)
+	GetAssignments(workOrderId string) ([]Assignment, error)
+	CountActiveByMechanic(mechanicId string) (int64, error)
+
+	GetServiceById(workOrderId, svcId string) (SvcWorkOrder, error)
+	UpdateService(m SvcWorkOrder, data map[string]interface{}) (int64, error)
+
+	GetPartById(workOrderId, partId string) (PartWorkOrder, error)
+	AddPart(part PartWorkOrder) (PartWorkOrder, error)
+	UpdatePartQuantity(part PartWorkOrder) (PartWorkOrder, error)
+	RemovePart(part PartWorkOrder) error
 }
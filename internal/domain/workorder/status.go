@@ -0,0 +1,106 @@
+package workorder
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"workshop-management/utils"
+)
+
+var (
+	ErrServicesPending = errors.New("all work order services must be done or skipped before completing")
+	ErrStatusChanged   = errors.New("work order status was changed by someone else, reload and try again")
+)
+
+// TransitionError is returned when a status change is not part of the work order (or service line)
+// lifecycle or the caller's role is not allowed to perform it.
+type TransitionError struct {
+	Entity string
+	From   string
+	To     string
+	Role   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("role %s is not allowed to move %s from '%s' to '%s'", e.Role, e.Entity, e.From, e.To)
+}
+
+var (
+	staffRoles = []string{utils.RoleAdmin, utils.RoleCashier}
+	shopRoles  = []string{utils.RoleAdmin, utils.RoleCashier, utils.RoleMechanic}
+)
+
+// transitions maps current status -> next status -> roles allowed to make the move.
+//
+//	open -> on progress -> waiting_parts / quality_check -> completed
+//	any non-final status -> cancelled
+var transitions = map[string]map[string][]string{
+	utils.StsOpen: {
+		utils.StsOnProgress: staffRoles,
+		utils.StsCancelled:  staffRoles,
+	},
+	utils.StsOnProgress: {
+		utils.StsWaitingParts: shopRoles,
+		utils.StsQualityCheck: shopRoles,
+		utils.StsCancelled:    staffRoles,
+	},
+	utils.StsWaitingParts: {
+		utils.StsOnProgress: shopRoles,
+		utils.StsCancelled:  staffRoles,
+	},
+	utils.StsQualityCheck: {
+		utils.StsOnProgress: shopRoles, // rework
+		utils.StsCompleted:  staffRoles,
+		utils.StsCancelled:  staffRoles,
+	},
+}
+
+// serviceTransitions is the lifecycle of a single service line inside a work order.
+// Lines are created as open (or pending, the column default).
+var serviceTransitions = map[string]map[string][]string{
+	utils.StsOpen: {
+		utils.StsStarted: shopRoles,
+		utils.StsSkipped: shopRoles,
+	},
+	utils.StsPending: {
+		utils.StsStarted: shopRoles,
+		utils.StsSkipped: shopRoles,
+	},
+	utils.StsStarted: {
+		utils.StsDone:    shopRoles,
+		utils.StsSkipped: shopRoles,
+	},
+}
+
+// CanTransition validates a work order status change for the given role.
+func CanTransition(from, to, role string) error {
+	roles, ok := transitions[from][to]
+	if !ok || !slices.Contains(roles, role) {
+		return &TransitionError{Entity: "work order", From: from, To: to, Role: role}
+	}
+	return nil
+}
+
+// CanTransitionService validates a service line status change for the given role.
+func CanTransitionService(from, to, role string) error {
+	roles, ok := serviceTransitions[from][to]
+	if !ok || !slices.Contains(roles, role) {
+		return &TransitionError{Entity: "work order service", From: from, To: to, Role: role}
+	}
+	return nil
+}
+
+// ServicesFinished reports whether every service line is done or skipped.
+func ServicesFinished(services []SvcWorkOrder) bool {
+	for _, svc := range services {
+		if svc.Status != utils.StsDone && svc.Status != utils.StsSkipped {
+			return false
+		}
+	}
+	return true
+}
+
+// IsFinal reports whether no further transitions are possible from status.
+func IsFinal(status string) bool {
+	return status == utils.StsCompleted || status == utils.StsCancelled
+}
@@ -1,14 +1,24 @@
 package workorder
 
 import (
+	"context"
 	"workshop-management/internal/dto"
 	"workshop-management/pkg/filter"
 )
 
 type Service interface {
+	WithContext(ctx context.Context) Service
+
 	CreateFromBooking(bookingId, userId string) (WorkOrder, error)
 	AssignMechanic(req dto.AssignMechanic, workOrderId, userId string) (int64, error)
 	GetById(id string) (WorkOrder, error)
-	UpdateStatus(workOrderId, status, userId string) (int64, error)
+	GetAssignments(workOrderId string) ([]Assignment, error)
+	GetStatusHistory(workOrderId, userId, role string) ([]StatusHistory, error)
+	UpdateStatus(req dto.UpdateStatus, workOrderId, userId, role string) (int64, error)
 	Fetch(params filter.BaseParams) ([]WorkOrder, int64, error)
+	UpdateNotes(req dto.UpdateWorkOrderNotes, workOrderId, userId, role string) (int64, error)
+	UpdateServiceStatus(workOrderId, svcId, status, userId, role string) (int64, error)
+	AddPart(req dto.AddWorkOrderPart, workOrderId, userId string) (PartWorkOrder, error)
+	UpdatePart(req dto.UpdateWorkOrderPart, workOrderId, partId, userId string) (PartWorkOrder, error)
+	RemovePart(workOrderId, partId, userId string) error
 }
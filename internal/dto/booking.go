@@ -3,19 +3,24 @@ package dto
 import "time"
 
 type CreateBooking struct {
-	VehicleID   string    `json:"vehicle_id" binding:"required"`
+	VehicleID   string    `json:"vehicle_id" binding:"required,uuid"`
 	BookingDate time.Time `json:"booking_date" binding:"required"`
-	Notes       string    `json:"notes"`
-	ServiceIDs  []string  `json:"service_ids" binding:"required"`
+	Notes       string    `json:"notes" binding:"max=255"`
+	ServiceIDs  []string  `json:"service_ids" binding:"required,min=1,dive,uuid"`
 }
 
 type UpdateBooking struct {
 	BookingDate time.Time `json:"booking_date"`
-	Notes       string    `json:"notes"`
-	ServiceIDs  []string  `json:"service_ids"`
-	Status      string    `json:"status"`
+	Notes       *string   `json:"notes" binding:"omitempty,max=255"`
+	ServiceIDs  []string  `json:"service_ids" binding:"omitempty,dive,uuid"`
+}
+
+type EstimateBooking struct {
+	VehicleID  string   `json:"vehicle_id" binding:"required,uuid"`
+	ServiceIDs []string `json:"service_ids" binding:"required,min=1,dive,uuid"`
 }
 
 type UpdateBookingStatus struct {
 	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason" binding:"max=255"`
 }
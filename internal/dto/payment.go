@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+type AddPayment struct {
+	Method string     `json:"method" binding:"required"`
+	Amount float64    `json:"amount" binding:"required,gt=0"`
+	PaidAt *time.Time `json:"paid_at"`
+}
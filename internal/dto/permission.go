@@ -0,0 +1,10 @@
+package dto
+
+type SetRolePermissions struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+type RolePermissions struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
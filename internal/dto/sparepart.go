@@ -0,0 +1,15 @@
+package dto
+
+type AddSparepart struct {
+	Code  string  `json:"code" binding:"required,max=50"`
+	Name  string  `json:"name" binding:"required,max=100"`
+	Stock int     `json:"stock" binding:"gte=0"`
+	Price float64 `json:"price" binding:"gte=0"`
+}
+
+type UpdateSparepart struct {
+	Code  string   `json:"code" binding:"omitempty,max=50"`
+	Name  string   `json:"name" binding:"omitempty,max=100"`
+	Stock *int     `json:"stock" binding:"omitempty,gte=0"`
+	Price *float64 `json:"price" binding:"omitempty,gte=0"`
+}
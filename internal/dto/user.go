@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type UserRegister struct {
 	Name     string `json:"name" binding:"required,min=3,max=100"`
 	Email    string `json:"email" binding:"required,email"`
@@ -22,3 +24,79 @@ type ChangePassword struct {
 	CurrentPassword string `json:"current_password" binding:"required,min=8,max=64"`
 	NewPassword     string `json:"new_password" binding:"required,min=8,max=64"`
 }
+
+type ForgotPassword struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPassword struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8,max=64"`
+}
+
+type RequestVerification struct {
+	Channel string `json:"channel" binding:"omitempty,oneof=email sms"`
+}
+
+type ConfirmVerification struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type RefreshToken struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type AuthToken struct {
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// LoginResult holds either the issued tokens or, when a second factor is needed, the challenge to answer.
+type LoginResult struct {
+	*AuthToken
+	*MfaChallenge
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+type MfaChallenge struct {
+	MfaRequired  bool      `json:"mfa_required"`
+	MfaToken     string    `json:"mfa_token"`
+	MfaExpiresAt time.Time `json:"mfa_expires_at"`
+	// Setup is returned when the role requires two-factor authentication and the user has not enrolled
+	// yet; the first valid code completes the enrollment.
+	Setup *TotpSetup `json:"setup,omitempty"`
+}
+
+type TotpSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type LoginMfa struct {
+	MfaToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required,max=20"`
+}
+
+type TotpCode struct {
+	Code string `json:"code" binding:"required,max=20"`
+}
+
+type CreateStaff struct {
+	Name     string `json:"name" binding:"required,min=3,max=100"`
+	Email    string `json:"email" binding:"required,email"`
+	Phone    string `json:"phone" binding:"required,min=9,max=15"`
+	Password string `json:"password" binding:"required,min=8,max=64"`
+	Role     string `json:"role" binding:"required,oneof=admin cashier mechanic"`
+}
+
+type ChangeRole struct {
+	Role string `json:"role" binding:"required,oneof=admin cashier mechanic customer"`
+}
+
+type BootstrapAdmin struct {
+	Name     string
+	Email    string
+	Phone    string
+	Password string
+}
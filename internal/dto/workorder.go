@@ -2,8 +2,23 @@ package dto
 
 type AssignMechanic struct {
 	MechanicID string `json:"mechanic_id" binding:"required,uuid"`
+	Reason     string `json:"reason" binding:"max=255"`
 }
 
 type UpdateStatus struct {
 	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+type UpdateWorkOrderNotes struct {
+	Notes string `json:"notes" binding:"required,max=1000"`
+}
+
+type AddWorkOrderPart struct {
+	SparepartID string `json:"sparepart_id" binding:"required,uuid"`
+	Quantity    int    `json:"quantity" binding:"required,min=1"`
+}
+
+type UpdateWorkOrderPart struct {
+	Quantity int `json:"quantity" binding:"required,min=1"`
 }
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	auditDomain "workshop-management/internal/domain/audit"
+	"workshop-management/internal/services/audit"
+	"workshop-management/pkg/filter"
+	"workshop-management/pkg/logger"
+	"workshop-management/pkg/messages"
+	"workshop-management/pkg/response"
+	"workshop-management/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HandlerAudit struct {
+	Service *audit.ServiceAudit
+}
+
+func NewAuditHandler(s *audit.ServiceAudit) *HandlerAudit {
+	return &HandlerAudit{Service: s}
+}
+
+// Fetch godoc
+// @Summary List audit events
+// @Description List who created, updated or deleted users, vehicles, services, bookings and work orders. Filter with filters[entity], filters[entity_id], filters[actor_id], filters[action], filters[log_id], filters[from] and filters[to].
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number for pagination"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Field to sort by (created_at, entity, action)"
+// @Param order_direction query string false "Sort direction (asc/desc)"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /audit [get]
+func (h *HandlerAudit) Fetch(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][HandlerAudit][Fetch]", logId)
+
+	params, _ := filter.GetBaseParams(ctx, "created_at", "desc", 20)
+	params.Filters = filter.WhitelistFilter(params.Filters, []string{"entity", "entity_id", "actor_id", "action", "log_id", "from", "to"})
+
+	data, totalData, err := h.Service.Fetch(params)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Fetch; Error: %+v", logPrefix, err))
+		code := http.StatusInternalServerError
+		if errors.Is(err, auditDomain.ErrInvalidTime) {
+			code = http.StatusBadRequest
+		}
+
+		res := response.Response(code, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(code, res)
+		return
+	}
+
+	res := response.PaginationResponse(http.StatusOK, int(totalData), params.Page, params.Limit, logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %d events;", logPrefix, len(data)))
+	ctx.JSON(http.StatusOK, res)
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	bookingDomain "workshop-management/internal/domain/booking"
 	"workshop-management/internal/dto"
+	"workshop-management/internal/policy"
 	"workshop-management/internal/services/booking"
 	"workshop-management/pkg/filter"
 	"workshop-management/pkg/logger"
@@ -34,12 +36,15 @@ func NewBookingHandler(s *booking.ServiceBooking) *HandlerBooking {
 // @Param        booking  body      dto.CreateBooking  true  "Booking details to be created"
 // @Success      201      {object}  response.Success  "Booking created successfully"
 // @Failure      400      {object}  response.Error    "Invalid request body"
+// @Failure      403      {object}  response.Error    "Customer has not verified their contact"
+// @Failure      404      {object}  response.Error    "Vehicle not found"
+// @Failure      409      {object}  response.Error    "Booking slot is full"
+// @Failure      422      {object}  response.Error    "Booking date is in the past or outside opening hours, or services cannot be booked"
 // @Failure      500      {object}  response.Error    "Internal server error"
 // @Security     ApiKeyAuth
 // @Router       /booking [post]
 func (h *HandlerBooking) Create(ctx *gin.Context) {
-	authData := utils.GetAuthData(ctx)
-	userId := utils.InterfaceString(authData["user_id"])
+	actor := policy.NewActor(utils.GetAuthData(ctx))
 	logId := utils.GenerateLogId(ctx)
 	logPrefix := fmt.Sprintf("[%s][HandlerBooking][Create]", logId)
 
@@ -54,12 +59,36 @@ func (h *HandlerBooking) Create(ctx *gin.Context) {
 	}
 	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
 
-	data, err := h.Service.Create(userId, req)
+	data, err := h.Service.WithContext(ctx).Create(actor, req)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Create; Error: %+v", logPrefix, err))
-		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
-		res.Error = err.Error()
-		ctx.JSON(http.StatusInternalServerError, res)
+		var servicesErr *bookingDomain.InvalidServicesError
+		switch {
+		case errors.Is(err, bookingDomain.ErrVehicleNotFound):
+			res := response.Response(http.StatusNotFound, messages.NotFound, logId, nil)
+			res.Error = response.Errors{Code: http.StatusNotFound, Message: err.Error()}
+			ctx.JSON(http.StatusNotFound, res)
+		case errors.As(err, &servicesErr):
+			res := response.Response(http.StatusUnprocessableEntity, messages.MsgFail, logId, nil)
+			res.Error = servicesErr.Services
+			ctx.JSON(http.StatusUnprocessableEntity, res)
+		case errors.Is(err, bookingDomain.ErrPastDate), errors.Is(err, bookingDomain.ErrOutsideHours):
+			res := response.Response(http.StatusUnprocessableEntity, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusUnprocessableEntity, Message: err.Error()}
+			ctx.JSON(http.StatusUnprocessableEntity, res)
+		case errors.Is(err, bookingDomain.ErrSlotFull):
+			res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+			ctx.JSON(http.StatusConflict, res)
+		case errors.Is(err, bookingDomain.ErrUnverifiedCustomer):
+			res := response.Response(http.StatusForbidden, messages.MsgDenied, logId, nil)
+			res.Error = response.Errors{Code: http.StatusForbidden, Message: err.Error()}
+			ctx.JSON(http.StatusForbidden, res)
+		default:
+			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+			res.Error = err.Error()
+			ctx.JSON(http.StatusInternalServerError, res)
+		}
 		return
 	}
 
@@ -68,6 +97,100 @@ func (h *HandlerBooking) Create(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, res)
 }
 
+// Estimate godoc
+// @Summary      Estimate the price of a booking
+// @Description  Price the selected services for a vehicle with the current catalog, discount and tax rules. Nothing is stored.
+// @Tags         Bookings
+// @Accept       json
+// @Produce      json
+// @Param        estimate  body      dto.EstimateBooking  true  "Vehicle and services to be priced"
+// @Success      200       {object}  response.Success  "Estimate calculated successfully"
+// @Failure      400       {object}  response.Error    "Invalid request body"
+// @Failure      404       {object}  response.Error    "Vehicle not found"
+// @Failure      422       {object}  response.Error    "Services cannot be booked"
+// @Failure      500       {object}  response.Error    "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /booking/estimate [post]
+func (h *HandlerBooking) Estimate(ctx *gin.Context) {
+	actor := policy.NewActor(utils.GetAuthData(ctx))
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][HandlerBooking][Estimate]", logId)
+
+	var req dto.EstimateBooking
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
+
+	data, err := h.Service.Estimate(actor, req)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Estimate; Error: %+v", logPrefix, err))
+		var servicesErr *bookingDomain.InvalidServicesError
+		switch {
+		case errors.Is(err, bookingDomain.ErrVehicleNotFound):
+			res := response.Response(http.StatusNotFound, messages.NotFound, logId, nil)
+			res.Error = response.Errors{Code: http.StatusNotFound, Message: err.Error()}
+			ctx.JSON(http.StatusNotFound, res)
+		case errors.As(err, &servicesErr):
+			res := response.Response(http.StatusUnprocessableEntity, messages.MsgFail, logId, nil)
+			res.Error = servicesErr.Services
+			ctx.JSON(http.StatusUnprocessableEntity, res)
+		default:
+			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+			res.Error = err.Error()
+			ctx.JSON(http.StatusInternalServerError, res)
+		}
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// AvailableSlots godoc
+// @Summary      List free booking slots
+// @Description  List the remaining bookable slots of a day based on opening hours, slot length and capacity.
+// @Tags         Bookings
+// @Accept       json
+// @Produce      json
+// @Param        date  query     string  true  "Date (YYYY-MM-DD)"
+// @Success      200   {object}  response.Success  "Free slots retrieved successfully"
+// @Failure      400   {object}  response.Error    "Invalid date"
+// @Failure      500   {object}  response.Error    "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /booking/slots [get]
+func (h *HandlerBooking) AvailableSlots(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][HandlerBooking][AvailableSlots]", logId)
+
+	date := ctx.Query("date")
+	data, err := h.Service.AvailableSlots(date)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.AvailableSlots; Error: %+v", logPrefix, err))
+		if errors.Is(err, bookingDomain.ErrInvalidDate) {
+			res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Date: %s; Response: %+v;", logPrefix, date, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
 // GetBookingById godoc
 // @Summary      Get a booking by ID
 // @Description  Retrieve booking details using the booking ID.
@@ -83,13 +206,14 @@ func (h *HandlerBooking) Create(ctx *gin.Context) {
 func (h *HandlerBooking) GetBookingById(ctx *gin.Context) {
 	logId := utils.GenerateLogId(ctx)
 	logPrefix := fmt.Sprintf("[%s][HandlerBooking][GetBookingById]", logId)
+	actor := policy.NewActor(utils.GetAuthData(ctx))
 
 	bookingId, err := utils.ValidateUUID(ctx, logId)
 	if err != nil {
 		return
 	}
 
-	data, err := h.Service.GetByID(bookingId)
+	data, err := h.Service.GetByID(actor, bookingId)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; GetByID; Error: %+v", logPrefix, err))
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -110,6 +234,49 @@ func (h *HandlerBooking) GetBookingById(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
+// GetHistory godoc
+// @Summary      Get the status history of a booking
+// @Description  List every status transition of a booking, oldest first, with who made it and why.
+// @Tags         Bookings
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Booking ID"
+// @Success      200  {object}  response.Success  "Booking status history retrieved successfully"
+// @Failure      404  {object}  response.Error    "Booking not found"
+// @Failure      500  {object}  response.Error    "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /booking/{id}/history [get]
+func (h *HandlerBooking) GetHistory(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][HandlerBooking][GetHistory]", logId)
+	actor := policy.NewActor(utils.GetAuthData(ctx))
+
+	bookingId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	data, err := h.Service.GetHistory(actor, bookingId)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; GetHistory; Error: %+v", logPrefix, err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res := response.Response(http.StatusNotFound, messages.NotFound, logId, nil)
+			res.Error = "booking not found"
+			ctx.JSON(http.StatusNotFound, res)
+			return
+		}
+
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
 // Fetch godoc
 // @Summary      Get a list of bookings
 // @Description  Retrieve a list of bookings with optional filters and pagination.
@@ -136,12 +303,7 @@ func (h *HandlerBooking) Fetch(ctx *gin.Context) {
 	params, _ := filter.GetBaseParams(ctx, "updated_at", "desc", 10)
 	params.Filters = filter.WhitelistFilter(params.Filters, []string{"user_id", "status"})
 
-	userId := utils.InterfaceString(authData["user_id"])
-	if utils.InterfaceString(authData["role"]) == utils.RoleCustomer {
-		params.Filters["user_id"] = userId
-	}
-
-	bookings, totalData, err := h.Service.Fetch(params)
+	bookings, totalData, err := h.Service.Fetch(policy.NewActor(authData), params)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Fetch; Error: %+v", logPrefix, err))
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -162,6 +324,135 @@ func (h *HandlerBooking) Fetch(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
+// Update godoc
+// @Summary      Update a booking
+// @Description  Reschedule a pending booking, change its notes or replace its services. Only the customer who made the booking can change it.
+// @Tags         Bookings
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string             true  "Booking ID"
+// @Param        booking  body      dto.UpdateBooking  true  "Booking details to be updated"
+// @Success      200      {object}  response.Success  "Booking updated successfully"
+// @Failure      400      {object}  response.Error    "Invalid request body"
+// @Failure      403      {object}  response.Error    "Booking belongs to another customer"
+// @Failure      404      {object}  response.Error    "Booking not found"
+// @Failure      409      {object}  response.Error    "Booking is no longer pending or the slot is full"
+// @Failure      422      {object}  response.Error    "Booking date is in the past or outside opening hours, or a service does not exist"
+// @Failure      500      {object}  response.Error    "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /booking/{id} [put]
+func (h *HandlerBooking) Update(ctx *gin.Context) {
+	actor := policy.NewActor(utils.GetAuthData(ctx))
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][HandlerBooking][Update]", logId)
+
+	bookingId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	var req dto.UpdateBooking
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
+
+	data, err := h.Service.WithContext(ctx).Update(actor, bookingId, req)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Update; Error: %+v", logPrefix, err))
+		var servicesErr *bookingDomain.InvalidServicesError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			res := response.Response(http.StatusNotFound, messages.NotFound, logId, nil)
+			res.Error = "booking not found"
+			ctx.JSON(http.StatusNotFound, res)
+		case errors.Is(err, bookingDomain.ErrNotOwner):
+			res := response.Response(http.StatusForbidden, messages.MsgDenied, logId, nil)
+			res.Error = response.Errors{Code: http.StatusForbidden, Message: err.Error()}
+			ctx.JSON(http.StatusForbidden, res)
+		case errors.Is(err, bookingDomain.ErrNotEditable), errors.Is(err, bookingDomain.ErrSlotFull):
+			res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+			ctx.JSON(http.StatusConflict, res)
+		case errors.As(err, &servicesErr):
+			res := response.Response(http.StatusUnprocessableEntity, messages.MsgFail, logId, nil)
+			res.Error = servicesErr.Services
+			ctx.JSON(http.StatusUnprocessableEntity, res)
+		case errors.Is(err, bookingDomain.ErrPastDate), errors.Is(err, bookingDomain.ErrOutsideHours):
+			res := response.Response(http.StatusUnprocessableEntity, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusUnprocessableEntity, Message: err.Error()}
+			ctx.JSON(http.StatusUnprocessableEntity, res)
+		default:
+			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+			res.Error = err.Error()
+			ctx.JSON(http.StatusInternalServerError, res)
+		}
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Update booking successfully", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// AcceptQuote godoc
+// @Summary      Accept the price quote of a booking
+// @Description  Price a pending booking with the current catalog, discount and tax rules and store the quote with it. The work order and invoice use the quoted prices. Only the customer who made the booking can accept it.
+// @Tags         Bookings
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Booking ID"
+// @Success      201  {object}  response.Success  "Quote accepted successfully"
+// @Failure      403  {object}  response.Error    "Booking belongs to another customer"
+// @Failure      404  {object}  response.Error    "Booking not found"
+// @Failure      409  {object}  response.Error    "Booking is no longer pending"
+// @Failure      500  {object}  response.Error    "Internal server error"
+// @Security     ApiKeyAuth
+// @Router       /booking/{id}/quote [post]
+func (h *HandlerBooking) AcceptQuote(ctx *gin.Context) {
+	actor := policy.NewActor(utils.GetAuthData(ctx))
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][HandlerBooking][AcceptQuote]", logId)
+
+	bookingId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	data, err := h.Service.WithContext(ctx).AcceptQuote(actor, bookingId)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.AcceptQuote; Error: %+v", logPrefix, err))
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			res := response.Response(http.StatusNotFound, messages.NotFound, logId, nil)
+			res.Error = "booking not found"
+			ctx.JSON(http.StatusNotFound, res)
+		case errors.Is(err, bookingDomain.ErrNotOwner):
+			res := response.Response(http.StatusForbidden, messages.MsgDenied, logId, nil)
+			res.Error = response.Errors{Code: http.StatusForbidden, Message: err.Error()}
+			ctx.JSON(http.StatusForbidden, res)
+		case errors.Is(err, bookingDomain.ErrNotEditable):
+			res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+			ctx.JSON(http.StatusConflict, res)
+		default:
+			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+			res.Error = err.Error()
+			ctx.JSON(http.StatusInternalServerError, res)
+		}
+		return
+	}
+
+	res := response.Response(http.StatusCreated, "Accept quote successfully", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusCreated, res)
+}
+
 // UpdateStatus godoc
 // @Summary      Update status a booking
 // @Description  Update status a booking with the provided details.
@@ -173,15 +464,14 @@ func (h *HandlerBooking) Fetch(ctx *gin.Context) {
 // @Success      200      {object}  response.Success  "Booking updated successfully"
 // @Failure      400      {object}  response.Error    "Invalid request body"
 // @Failure      404      {object}  response.Error    "Booking not found"
+// @Failure      409      {object}  response.Error    "Booking status was changed concurrently"
 // @Failure      500      {object}  response.Error    "Internal server error"
 // @Security     ApiKeyAuth
 // @Router       /booking/{id}/status [put]
 func (h *HandlerBooking) UpdateStatus(ctx *gin.Context) {
 	logId := utils.GenerateLogId(ctx)
 	logPrefix := fmt.Sprintf("[%s][HandlerBooking][Update]", logId)
-	authData := utils.GetAuthData(ctx)
-	role := utils.InterfaceString(authData["role"])
-	userId := utils.InterfaceString(authData["user_id"])
+	actor := policy.NewActor(utils.GetAuthData(ctx))
 
 	bookingId, err := utils.ValidateUUID(ctx, logId)
 	if err != nil {
@@ -198,9 +488,21 @@ func (h *HandlerBooking) UpdateStatus(ctx *gin.Context) {
 	}
 	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
 
-	rows, err := h.Service.UpdateStatus(bookingId, userId, role, req)
+	rows, err := h.Service.WithContext(ctx).UpdateStatus(actor, bookingId, req)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Update; Error: %+v", logPrefix, err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+			res.Error = response.Errors{Code: http.StatusNotFound, Message: messages.NotFound}
+			ctx.JSON(http.StatusNotFound, res)
+			return
+		}
+		if errors.Is(err, bookingDomain.ErrStatusChanged) {
+			res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+			ctx.JSON(http.StatusConflict, res)
+			return
+		}
 		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
 		res.Error = err.Error()
 		ctx.JSON(http.StatusBadRequest, res)
@@ -0,0 +1,159 @@
+package invoice
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"workshop-management/internal/domain/invoice"
+	"workshop-management/pkg/filter"
+	"workshop-management/pkg/logger"
+	"workshop-management/pkg/messages"
+	"workshop-management/pkg/response"
+	"workshop-management/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type HandlerInvoice struct {
+	Service invoice.Service
+}
+
+func NewInvoiceHandler(s invoice.Service) *HandlerInvoice {
+	return &HandlerInvoice{
+		Service: s,
+	}
+}
+
+// Fetch godoc
+// @Summary Get all invoices
+// @Description Get all invoices
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number for pagination"
+// @Param limit query int false "Number of items per page"
+// @Param order_by query string false "Field to sort by"
+// @Param order_direction query string false "Sort direction (asc/desc)"
+// @Param filters[status] query string false "Filter by invoice status"
+// @Param filters[work_order_id] query string false "Filter by work order ID"
+// @Success 200 {object} response.Success
+// @Failure 500 {object} response.Error
+// @Router /invoices [get]
+// @Security Bearer
+func (h *HandlerInvoice) Fetch(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][InvoiceHandler][Fetch]", logId)
+
+	params, _ := filter.GetBaseParams(ctx, "updated_at", "desc", 10)
+	params.Filters = filter.WhitelistFilter(params.Filters, []string{"status", "work_order_id"})
+
+	data, totalData, err := h.Service.Fetch(params)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Fetch; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.PaginationResponse(http.StatusOK, int(totalData), params.Page, params.Limit, logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GetById godoc
+// @Summary Get an invoice by ID
+// @Description Get an invoice and its line items by ID
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /invoice/{id} [get]
+// @Security Bearer
+func (h *HandlerInvoice) GetById(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][InvoiceHandler][GetById]", logId)
+
+	invoiceId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	data, err := h.Service.GetById(invoiceId)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; GetById; Error: %+v", logPrefix, err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res := response.Response(http.StatusNotFound, messages.NotFound, logId, nil)
+			res.Error = "invoice not found"
+			ctx.JSON(http.StatusNotFound, res)
+			return
+		}
+
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// CreateFromWorkOrder godoc
+// @Summary Generate an invoice from a work order
+// @Description Generate an invoice for a completed work order; the total is the sum of its services and parts
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Param id path string true "Work Order ID"
+// @Success 201 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /invoice/from-workorder/{id} [post]
+// @Security Bearer
+func (h *HandlerInvoice) CreateFromWorkOrder(ctx *gin.Context) {
+	authData := utils.GetAuthData(ctx)
+	userId := utils.InterfaceString(authData["user_id"])
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][InvoiceHandler][CreateFromWorkOrder]", logId)
+
+	workOrderId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	data, err := h.Service.CreateFromWorkOrder(workOrderId, userId)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.CreateFromWorkOrder; Error: %+v", logPrefix, err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res := response.Response(http.StatusNotFound, messages.NotFound, logId, nil)
+			res.Error = "work order not found"
+			ctx.JSON(http.StatusNotFound, res)
+			return
+		}
+
+		if errors.Is(err, invoice.ErrNotCompleted) || errors.Is(err, invoice.ErrAlreadyInvoiced) {
+			res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+			ctx.JSON(http.StatusConflict, res)
+			return
+		}
+
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusCreated, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusCreated, res)
+}
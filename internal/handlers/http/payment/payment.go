@@ -0,0 +1,179 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"workshop-management/internal/domain/payment"
+	"workshop-management/internal/dto"
+	"workshop-management/pkg/logger"
+	"workshop-management/pkg/messages"
+	"workshop-management/pkg/response"
+	"workshop-management/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type HandlerPayment struct {
+	Service payment.Service
+}
+
+func NewPaymentHandler(s payment.Service) *HandlerPayment {
+	return &HandlerPayment{
+		Service: s,
+	}
+}
+
+// Create godoc
+// @Summary Record a payment
+// @Description Record a (partial) payment against an invoice. The invoice moves to partially_paid or paid once payments cover its total.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Param payment body dto.AddPayment true "Payment details"
+// @Success 201 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /invoice/{id}/payments [post]
+// @Security Bearer
+func (h *HandlerPayment) Create(ctx *gin.Context) {
+	authData := utils.GetAuthData(ctx)
+	userId := utils.InterfaceString(authData["user_id"])
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][PaymentHandler][Create]", logId)
+
+	invoiceId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	var req dto.AddPayment
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
+
+	data, inv, err := h.Service.Create(req, invoiceId, userId)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Create; Error: %+v", logPrefix, err))
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			res := response.Response(http.StatusNotFound, messages.NotFound, logId, nil)
+			res.Error = "invoice not found"
+			ctx.JSON(http.StatusNotFound, res)
+		case errors.Is(err, payment.ErrInvalidMethod), errors.Is(err, payment.ErrInvalidAmount):
+			res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+			ctx.JSON(http.StatusBadRequest, res)
+		case errors.Is(err, payment.ErrOverpayment), errors.Is(err, payment.ErrInvoiceClosed):
+			res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+			ctx.JSON(http.StatusConflict, res)
+		default:
+			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+			res.Error = err.Error()
+			ctx.JSON(http.StatusInternalServerError, res)
+		}
+		return
+	}
+
+	result := map[string]interface{}{"payment": data, "invoice": inv}
+	res := response.Response(http.StatusCreated, "Payment recorded successfully", logId, result)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(result)))
+	ctx.JSON(http.StatusCreated, res)
+}
+
+// GetByInvoiceId godoc
+// @Summary List payments of an invoice
+// @Description List every payment recorded against an invoice
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /invoice/{id}/payments [get]
+// @Security Bearer
+func (h *HandlerPayment) GetByInvoiceId(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][PaymentHandler][GetByInvoiceId]", logId)
+
+	invoiceId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	data, err := h.Service.GetByInvoiceId(invoiceId)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.GetByInvoiceId; Error: %+v", logPrefix, err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res := response.Response(http.StatusNotFound, messages.NotFound, logId, nil)
+			res.Error = "invoice not found"
+			ctx.JSON(http.StatusNotFound, res)
+			return
+		}
+
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GetById godoc
+// @Summary Get a payment by ID
+// @Description Get a payment by ID
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /payment/{id} [get]
+// @Security Bearer
+func (h *HandlerPayment) GetById(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][PaymentHandler][GetById]", logId)
+
+	paymentId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	data, err := h.Service.GetById(paymentId)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.GetById; Error: %+v", logPrefix, err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res := response.Response(http.StatusNotFound, messages.NotFound, logId, nil)
+			res.Error = "payment not found"
+			ctx.JSON(http.StatusNotFound, res)
+			return
+		}
+
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
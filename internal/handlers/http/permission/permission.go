@@ -0,0 +1,134 @@
+package permission
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	permissionDomain "workshop-management/internal/domain/permission"
+	"workshop-management/internal/dto"
+	"workshop-management/internal/services/permission"
+	"workshop-management/pkg/logger"
+	"workshop-management/pkg/messages"
+	"workshop-management/pkg/response"
+	"workshop-management/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HandlerPermission struct {
+	Service *permission.ServicePermission
+}
+
+func NewPermissionHandler(s *permission.ServicePermission) *HandlerPermission {
+	return &HandlerPermission{Service: s}
+}
+
+// Fetch godoc
+// @Summary List permissions
+// @Description List every permission that can be granted to a role
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Success
+// @Security ApiKeyAuth
+// @Router /permissions [get]
+func (h *HandlerPermission) Fetch(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][HandlerPermission][Fetch]", logId)
+
+	data := h.Service.GetRegistry()
+
+	res := response.Response(http.StatusOK, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// FetchRoles godoc
+// @Summary List role permissions
+// @Description List the permissions granted to each role
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Success
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /permissions/roles [get]
+func (h *HandlerPermission) FetchRoles(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][HandlerPermission][FetchRoles]", logId)
+
+	data, err := h.Service.GetRoles()
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.GetRoles; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// UpdateRole godoc
+// @Summary Set role permissions
+// @Description Replace the permissions granted to a role. The admin role always has every permission and cannot be edited.
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Param role path string true "Role (cashier, mechanic or customer)"
+// @Param permissions body dto.SetRolePermissions true "Permissions to grant"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 422 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /permissions/roles/{role} [put]
+func (h *HandlerPermission) UpdateRole(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][HandlerPermission][UpdateRole]", logId)
+	userId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+	role := ctx.Param("role")
+
+	var req dto.SetRolePermissions
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Role: %s; Request: %+v;", logPrefix, role, utils.JsonEncode(req)))
+
+	data, err := h.Service.SetRolePermissions(userId, role, req)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.SetRolePermissions; Error: %+v", logPrefix, err))
+		switch {
+		case errors.Is(err, permissionDomain.ErrInvalidRole):
+			res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+			res.Error = response.Errors{Code: http.StatusNotFound, Message: err.Error()}
+			ctx.JSON(http.StatusNotFound, res)
+		case errors.Is(err, permissionDomain.ErrImmutableRole):
+			res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+			ctx.JSON(http.StatusConflict, res)
+		case errors.Is(err, permissionDomain.ErrUnknownPermission):
+			res := response.Response(http.StatusUnprocessableEntity, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusUnprocessableEntity, Message: err.Error()}
+			ctx.JSON(http.StatusUnprocessableEntity, res)
+		default:
+			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+			res.Error = err.Error()
+			ctx.JSON(http.StatusInternalServerError, res)
+		}
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Role permissions updated successfully", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
@@ -55,7 +55,7 @@ func (h *HandlerService) Create(ctx *gin.Context) {
 	}
 	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
 
-	data, err := h.Service.Create(userId, req)
+	data, err := h.Service.WithContext(ctx).Create(userId, req)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Create; Error: %+v", logPrefix, err))
 		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
@@ -181,7 +181,7 @@ func (h *HandlerService) Update(ctx *gin.Context) {
 	}
 	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
 
-	rows, err := h.Service.Update(userId, serviceId, req)
+	rows, err := h.Service.WithContext(ctx).Update(userId, serviceId, req)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Update; Error: %+v", logPrefix, err))
 		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
@@ -225,7 +225,7 @@ func (h *HandlerService) Delete(ctx *gin.Context) {
 		return
 	}
 
-	if err = h.Service.Delete(userId, serviceId); err != nil {
+	if err = h.Service.WithContext(ctx).Delete(userId, serviceId); err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Delete; Error: %+v", logPrefix, err))
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
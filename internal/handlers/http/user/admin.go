@@ -0,0 +1,286 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	userDomain "workshop-management/internal/domain/user"
+	"workshop-management/internal/dto"
+	"workshop-management/pkg/logger"
+	"workshop-management/pkg/messages"
+	"workshop-management/pkg/response"
+	"workshop-management/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateStaff godoc
+// @Summary Create a staff account
+// @Description Create an admin, cashier or mechanic account
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param user body dto.CreateStaff true "Staff account details"
+// @Success 201 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/staff [post]
+func (h *HandlerUser) CreateStaff(ctx *gin.Context) {
+	var req dto.CreateStaff
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][CreateStaff]", logId)
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(map[string]string{"name": req.Name, "email": req.Email, "phone": req.Phone, "role": req.Role})))
+
+	data, err := h.Service.WithContext(ctx).CreateStaff(req)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.CreateStaff; Error: %+v", logPrefix, err))
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			res := response.Response(http.StatusBadRequest, messages.MsgExists, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: "email or phone already exists"}
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+
+		adminError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusCreated, "Staff account created successfully", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusCreated, res)
+}
+
+// ChangeRole godoc
+// @Summary Change a user's role
+// @Description Change the role of a user. The user's existing sessions are revoked.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param role body dto.ChangeRole true "New role"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/{id}/role [put]
+func (h *HandlerUser) ChangeRole(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][ChangeRole]", logId)
+	adminId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+
+	id, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	var req dto.ChangeRole
+	if err = ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
+
+	data, err := h.Service.WithContext(ctx).ChangeRole(id, adminId, req)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.ChangeRole; Error: %+v", logPrefix, err))
+		adminError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "User role changed successfully", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Deactivate godoc
+// @Summary Deactivate a user
+// @Description Deactivate an account: the user is logged out everywhere and can no longer log in
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Success
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/{id}/deactivate [put]
+func (h *HandlerUser) Deactivate(ctx *gin.Context) {
+	h.setActive(ctx, "Deactivate", false)
+}
+
+// Activate godoc
+// @Summary Reactivate a user
+// @Description Reactivate a deactivated account
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Success
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/{id}/activate [put]
+func (h *HandlerUser) Activate(ctx *gin.Context) {
+	h.setActive(ctx, "Activate", true)
+}
+
+func (h *HandlerUser) setActive(ctx *gin.Context, method string, active bool) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][%s]", logId, method)
+	adminId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+
+	id, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	data, err := h.Service.WithContext(ctx).SetActive(id, adminId, active)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.SetActive; Error: %+v", logPrefix, err))
+		adminError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, fmt.Sprintf("User with ID: '%s' updated successfully", id), logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// ForceResetPassword godoc
+// @Summary Force a password reset
+// @Description Invalidate the user's password and sessions and email them a password reset link
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Success
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/{id}/force-reset [post]
+func (h *HandlerUser) ForceResetPassword(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][ForceResetPassword]", logId)
+
+	id, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	if err = h.Service.WithContext(ctx).ForceResetPassword(id); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.ForceResetPassword; Error: %+v", logPrefix, err))
+		adminError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Password reset link has been sent to the user", logId, nil)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: password reset forced for %s", logPrefix, id))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Unlock godoc
+// @Summary Unlock an account
+// @Description Clear the failed login attempts and lockout of an account
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Success
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/{id}/unlock [post]
+func (h *HandlerUser) Unlock(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][Unlock]", logId)
+
+	id, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	if err = h.Service.UnlockAccount(id); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.UnlockAccount; Error: %+v", logPrefix, err))
+		adminError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Account unlocked successfully", logId, nil)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: account %s unlocked", logPrefix, id))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// ResetTotp godoc
+// @Summary Reset a user's two-factor authentication
+// @Description Remove the two-factor enrollment of a user who lost their authenticator and recovery codes
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Success
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/{id}/2fa [delete]
+func (h *HandlerUser) ResetTotp(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][ResetTotp]", logId)
+	adminId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+
+	id, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	if err = h.Service.WithContext(ctx).ResetTotp(id, adminId); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.ResetTotp; Error: %+v", logPrefix, err))
+		adminError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Two-factor authentication reset successfully", logId, nil)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: two-factor reset for %s", logPrefix, id))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func adminError(ctx *gin.Context, logId uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+		res.Error = response.Errors{Code: http.StatusNotFound, Message: "user not found"}
+		ctx.JSON(http.StatusNotFound, res)
+	case errors.Is(err, userDomain.ErrInvalidRole):
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, userDomain.ErrSelfModification):
+		res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+		ctx.JSON(http.StatusConflict, res)
+	default:
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+	}
+}
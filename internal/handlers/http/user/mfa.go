@@ -0,0 +1,224 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"workshop-management/internal/domain/auth"
+	"workshop-management/internal/dto"
+	"workshop-management/pkg/logger"
+	"workshop-management/pkg/messages"
+	"workshop-management/pkg/response"
+	"workshop-management/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginMfa godoc
+// @Summary Complete a two-factor login
+// @Description Exchange the mfa_token returned by /user/login and a TOTP or recovery code for the session tokens.
+// @Description When the login returned a setup, the first valid code completes the enrollment and the recovery codes are returned once.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param login body dto.LoginMfa true "Two-factor challenge and code"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 429 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /user/login/2fa [post]
+func (h *HandlerUser) LoginMfa(ctx *gin.Context) {
+	var req dto.LoginMfa
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserController][LoginMfa]", logId)
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	data, err := h.Service.WithContext(ctx).VerifyMfaLogin(req, ctx.Request.UserAgent(), ctx.ClientIP(), logId.String())
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.VerifyMfaLogin; ERROR: %s;", logPrefix, err))
+		loginError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: token issued, expires at %s;", logPrefix, data.ExpiresAt))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// SetupTotp godoc
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and its otpauth:// provisioning URI (to render as a QR code). Confirm it with /user/2fa/enable.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} response.Success
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/2fa/setup [post]
+func (h *HandlerUser) SetupTotp(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][SetupTotp]", logId)
+	userId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+
+	data, err := h.Service.WithContext(ctx).SetupTotp(userId)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.SetupTotp; Error: %+v", logPrefix, err))
+		mfaError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Scan the QR code with your authenticator app", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: two-factor setup started for %s", logPrefix, userId))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// EnableTotp godoc
+// @Summary Enable two-factor authentication
+// @Description Confirm the enrollment with a code from the authenticator app. The recovery codes are only returned once.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param code body dto.TotpCode true "TOTP code"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/2fa/enable [post]
+func (h *HandlerUser) EnableTotp(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][EnableTotp]", logId)
+	userId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+
+	var req dto.TotpCode
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	codes, err := h.Service.WithContext(ctx).EnableTotp(userId, req)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.EnableTotp; Error: %+v", logPrefix, err))
+		mfaError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Two-factor authentication enabled", logId, gin.H{"recovery_codes": codes})
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: two-factor enabled for %s", logPrefix, userId))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// DisableTotp godoc
+// @Summary Disable two-factor authentication
+// @Description Disable two-factor authentication with a TOTP or recovery code. Not allowed for roles where it is mandatory.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param code body dto.TotpCode true "TOTP or recovery code"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/2fa/disable [post]
+func (h *HandlerUser) DisableTotp(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][DisableTotp]", logId)
+	userId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+
+	var req dto.TotpCode
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := h.Service.WithContext(ctx).DisableTotp(userId, req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.DisableTotp; Error: %+v", logPrefix, err))
+		mfaError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Two-factor authentication disabled", logId, nil)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: two-factor disabled for %s", logPrefix, userId))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes after confirming a TOTP code. The new codes are only returned once.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param code body dto.TotpCode true "TOTP code"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/2fa/recovery-codes [post]
+func (h *HandlerUser) RegenerateRecoveryCodes(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][RegenerateRecoveryCodes]", logId)
+	userId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+
+	var req dto.TotpCode
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	codes, err := h.Service.WithContext(ctx).RegenerateRecoveryCodes(userId, req)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.RegenerateRecoveryCodes; Error: %+v", logPrefix, err))
+		mfaError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Recovery codes regenerated", logId, gin.H{"recovery_codes": codes})
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: recovery codes regenerated for %s", logPrefix, userId))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func mfaError(ctx *gin.Context, logId uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+		res.Error = response.Errors{Code: http.StatusNotFound, Message: "user not found"}
+		ctx.JSON(http.StatusNotFound, res)
+	case errors.Is(err, auth.ErrInvalidMfaCode):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, auth.ErrMfaAlreadyEnabled), errors.Is(err, auth.ErrMfaNotEnabled),
+		errors.Is(err, auth.ErrMfaSetupNotStarted), errors.Is(err, auth.ErrMfaRequiredForRole):
+		res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+		ctx.JSON(http.StatusConflict, res)
+	default:
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+	}
+}
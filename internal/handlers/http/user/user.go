@@ -3,8 +3,13 @@ package user
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"reflect"
+	"strconv"
+	"time"
+	"workshop-management/internal/domain/auth"
+	userDomain "workshop-management/internal/domain/user"
 	"workshop-management/internal/dto"
 	"workshop-management/internal/services/user"
 	"workshop-management/pkg/filter"
@@ -14,6 +19,7 @@ import (
 	"workshop-management/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -51,7 +57,7 @@ func (h *HandlerUser) Register(ctx *gin.Context) {
 	}
 	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
 
-	data, err := h.Service.RegisterUser(req)
+	data, err := h.Service.WithContext(ctx).RegisterUser(req)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.RegisterUser; Error: %+v", logPrefix, err))
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
@@ -75,13 +81,16 @@ func (h *HandlerUser) Register(ctx *gin.Context) {
 
 // Login godoc
 // @Summary Login a user
-// @Description Login a user
+// @Description Login a user. Repeated failures lock the account and the client IP for an increasing period.
+// @Description Users with two-factor authentication get an mfa_token to complete the login at /user/login/2fa.
 // @Tags Users
 // @Accept  json
 // @Produce  json
 // @Param user body dto.Login true "User login details"
 // @Success 200 {object} response.Success
 // @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 429 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /user/login [post]
 func (h *HandlerUser) Login(ctx *gin.Context) {
@@ -97,26 +106,24 @@ func (h *HandlerUser) Login(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
-	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(map[string]string{"email": req.Email})))
 
-	token, err := h.Service.LoginUser(req, logId.String())
+	data, err := h.Service.WithContext(ctx).LoginUser(req, ctx.Request.UserAgent(), ctx.ClientIP(), logId.String())
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.LoginUser; ERROR: %s;", logPrefix, err))
-		if errors.Is(err, gorm.ErrRecordNotFound) || err.Error() == messages.ErrHashPassword {
-			res := response.Response(http.StatusBadRequest, messages.InvalidCred, logId, nil)
-			res.Error = response.Errors{Code: http.StatusBadRequest, Message: messages.MsgCredential}
-			ctx.JSON(http.StatusBadRequest, res)
-			return
-		}
+		loginError(ctx, logId, err)
+		return
+	}
 
-		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
-		res.Error = err.Error()
-		ctx.JSON(http.StatusInternalServerError, res)
+	if data.MfaChallenge != nil {
+		res := response.Response(http.StatusOK, "Two-factor code required", logId, data)
+		logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: two-factor challenge issued, expires at %s;", logPrefix, data.MfaExpiresAt))
+		ctx.JSON(http.StatusOK, res)
 		return
 	}
 
-	res := response.Response(http.StatusOK, "success", logId, map[string]interface{}{"token": token})
-	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(token)))
+	res := response.Response(http.StatusOK, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: token issued, expires at %s;", logPrefix, data.ExpiresAt))
 	ctx.JSON(http.StatusOK, res)
 }
 
@@ -143,7 +150,7 @@ func (h *HandlerUser) Logout(ctx *gin.Context) {
 		return
 	}
 
-	if err := h.Service.LogoutUser(token.(string)); err != nil {
+	if err := h.Service.LogoutUser(token.(string), utils.GetAuthData(ctx)); err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.LogoutUser; Error: %+v", logPrefix, err))
 		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
 		res.Error = err.Error()
@@ -302,7 +309,7 @@ func (h *HandlerUser) Update(ctx *gin.Context) {
 		return
 	}
 
-	data, err := h.Service.Update(userId, req)
+	data, err := h.Service.WithContext(ctx).Update(userId, req)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Update; ERROR: %s;", logPrefix, err))
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -353,7 +360,7 @@ func (h *HandlerUser) ChangePassword(ctx *gin.Context) {
 		return
 	}
 
-	data, err := h.Service.ChangePassword(userId, req)
+	data, err := h.Service.WithContext(ctx).ChangePassword(userId, req)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.ChangePassword; ERROR: %s;", logPrefix, err))
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -399,7 +406,7 @@ func (h *HandlerUser) Delete(ctx *gin.Context) {
 	authData := utils.GetAuthData(ctx)
 	userId := utils.InterfaceString(authData["user_id"])
 
-	if err := h.Service.Delete(userId); err != nil {
+	if err := h.Service.WithContext(ctx).Delete(userId); err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Delete; ERROR: %s;", logPrefix, err))
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
@@ -418,3 +425,265 @@ func (h *HandlerUser) Delete(ctx *gin.Context) {
 	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: User deleted successfully", logPrefix))
 	ctx.JSON(http.StatusOK, res)
 }
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Send a single-use password reset link to the given email. The response is the same whether or not the account exists.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param request body dto.ForgotPassword true "Account email"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /forgot-password [post]
+func (h *HandlerUser) ForgotPassword(ctx *gin.Context) {
+	var req dto.ForgotPassword
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][ForgotPassword]", logId)
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
+
+	if err := h.Service.ForgotPassword(req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.ForgotPassword; ERROR: %s;", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "If an account with that email exists, a password reset link has been sent.", logId, nil)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: password reset requested", logPrefix))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// ResetPassword godoc
+// @Summary Reset a password
+// @Description Set a new password using a reset token. All existing sessions of the user are revoked.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param request body dto.ResetPassword true "Reset token and new password"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /reset-password [post]
+func (h *HandlerUser) ResetPassword(ctx *gin.Context) {
+	var req dto.ResetPassword
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][ResetPassword]", logId)
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := h.Service.WithContext(ctx).ResetPassword(req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.ResetPassword; ERROR: %s;", logPrefix, err))
+		if errors.Is(err, auth.ErrInvalidResetToken) {
+			res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+			res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Password has been reset successfully. Please login with your new password.", logId, nil)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: password reset", logPrefix))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Refresh godoc
+// @Summary Refresh an access token
+// @Description Exchange a refresh token for a new access token and refresh token. Each refresh token can only be used once; re-using one revokes its session.
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param request body dto.RefreshToken true "Refresh token"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 401 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /user/refresh [post]
+func (h *HandlerUser) Refresh(ctx *gin.Context) {
+	var req dto.RefreshToken
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][Refresh]", logId)
+
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	token, err := h.Service.Refresh(req, logId.String())
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Refresh; ERROR: %s;", logPrefix, err))
+		if errors.Is(err, auth.ErrInvalidRefreshToken) || errors.Is(err, auth.ErrRefreshTokenReused) ||
+			errors.Is(err, userDomain.ErrAccountDisabled) || errors.Is(err, gorm.ErrRecordNotFound) {
+			res := response.Response(http.StatusUnauthorized, messages.MsgFail, logId, nil)
+			res.Error = "Please login and try again"
+			ctx.JSON(http.StatusUnauthorized, res)
+			return
+		}
+
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, token)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: token refreshed, expires at %s;", logPrefix, token.ExpiresAt))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GetSessions godoc
+// @Summary List active sessions
+// @Description List the active logins of the authenticated user
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} response.Success
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/sessions [get]
+func (h *HandlerUser) GetSessions(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][GetSessions]", logId)
+	authData := utils.GetAuthData(ctx)
+
+	data, err := h.Service.GetSessions(utils.InterfaceString(authData["user_id"]), utils.InterfaceString(authData["sid"]))
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.GetSessions; ERROR: %s;", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// RevokeOtherSessions godoc
+// @Summary Revoke other sessions
+// @Description Log the authenticated user out of every session except the current one
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} response.Success
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/sessions [delete]
+func (h *HandlerUser) RevokeOtherSessions(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][RevokeOtherSessions]", logId)
+	authData := utils.GetAuthData(ctx)
+
+	if err := h.Service.RevokeOtherSessions(utils.InterfaceString(authData["user_id"]), utils.InterfaceString(authData["sid"])); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.RevokeOtherSessions; ERROR: %s;", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Other sessions revoked successfully", logId, nil)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: other sessions revoked", logPrefix))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Log the authenticated user out of one of their sessions
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Session ID"
+// @Success 200 {object} response.Success
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/sessions/{id} [delete]
+func (h *HandlerUser) RevokeSession(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][RevokeSession]", logId)
+	authData := utils.GetAuthData(ctx)
+
+	sessionId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	rows, err := h.Service.RevokeSession(utils.InterfaceString(authData["user_id"]), sessionId)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.RevokeSession; ERROR: %s;", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+	if rows == 0 {
+		res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+		res.Error = response.Errors{Code: http.StatusNotFound, Message: "session not found"}
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, fmt.Sprintf("Session with ID: '%s' revoked successfully", sessionId), logId, nil)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: session %s revoked", logPrefix, sessionId))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func loginError(ctx *gin.Context, logId uuid.UUID, err error) {
+	var locked *auth.LockedError
+	switch {
+	case errors.Is(err, userDomain.ErrInvalidCredentials):
+		res := response.Response(http.StatusBadRequest, messages.InvalidCred, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: messages.MsgCredential}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, auth.ErrInvalidMfaCode):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, auth.ErrInvalidMfaToken):
+		res := response.Response(http.StatusUnauthorized, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusUnauthorized, Message: err.Error()}
+		ctx.JSON(http.StatusUnauthorized, res)
+	case errors.As(err, &locked):
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(locked.Until).Seconds()))))
+		res := response.Response(http.StatusTooManyRequests, messages.MsgDenied, logId, nil)
+		res.Error = response.Errors{Code: http.StatusTooManyRequests, Message: err.Error()}
+		ctx.JSON(http.StatusTooManyRequests, res)
+	case errors.Is(err, userDomain.ErrAccountDisabled):
+		res := response.Response(http.StatusForbidden, messages.MsgDenied, logId, nil)
+		res.Error = response.Errors{Code: http.StatusForbidden, Message: err.Error()}
+		ctx.JSON(http.StatusForbidden, res)
+	default:
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+	}
+}
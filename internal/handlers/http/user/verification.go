@@ -0,0 +1,118 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"workshop-management/internal/domain/auth"
+	"workshop-management/internal/dto"
+	"workshop-management/pkg/logger"
+	"workshop-management/pkg/messages"
+	"workshop-management/pkg/response"
+	"workshop-management/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestVerification godoc
+// @Summary Send a verification code
+// @Description Send a one-time code to the authenticated user's email or phone number
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param verification body dto.RequestVerification false "Channel to send the code over (email or sms)"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 429 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/verification [post]
+func (h *HandlerUser) RequestVerification(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][RequestVerification]", logId)
+	userId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+
+	var req dto.RequestVerification
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.BindJSON(&req); err != nil {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+			res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+			res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+			ctx.JSON(http.StatusBadRequest, res)
+			return
+		}
+	}
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
+
+	if err := h.Service.RequestVerification(userId, req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.RequestVerification; Error: %+v", logPrefix, err))
+		verificationError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Verification code sent", logId, nil)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Success: verification code sent to %s", logPrefix, userId))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// ConfirmVerification godoc
+// @Summary Confirm a verification code
+// @Description Verify the authenticated user's contact with the code they received
+// @Tags Users
+// @Accept  json
+// @Produce  json
+// @Param verification body dto.ConfirmVerification true "Verification code"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Security ApiKeyAuth
+// @Router /user/verification/confirm [post]
+func (h *HandlerUser) ConfirmVerification(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][UserHandler][ConfirmVerification]", logId)
+	userId := utils.InterfaceString(utils.GetAuthData(ctx)["user_id"])
+
+	var req dto.ConfirmVerification
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	data, err := h.Service.WithContext(ctx).ConfirmVerification(userId, req)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.ConfirmVerification; Error: %+v", logPrefix, err))
+		verificationError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "Account verified successfully", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func verificationError(ctx *gin.Context, logId uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, auth.ErrInvalidVerificationCode):
+		res := response.Response(http.StatusBadRequest, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: err.Error()}
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, auth.ErrAlreadyVerified):
+		res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+		ctx.JSON(http.StatusConflict, res)
+	case errors.Is(err, auth.ErrVerificationTooSoon):
+		res := response.Response(http.StatusTooManyRequests, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusTooManyRequests, Message: err.Error()}
+		ctx.JSON(http.StatusTooManyRequests, res)
+	default:
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strconv"
 	"workshop-management/internal/dto"
+	"workshop-management/internal/policy"
 	"workshop-management/internal/services/vehicle"
 	"workshop-management/pkg/filter"
 	"workshop-management/pkg/logger"
@@ -55,7 +56,7 @@ func (h *HandlerVehicle) Create(ctx *gin.Context) {
 	}
 	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
 
-	data, err := h.Service.Create(userId, req)
+	data, err := h.Service.WithContext(ctx).Create(userId, req)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Create; Error: %+v", logPrefix, err))
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
@@ -92,13 +93,14 @@ func (h *HandlerVehicle) Create(ctx *gin.Context) {
 func (h *HandlerVehicle) GetById(ctx *gin.Context) {
 	logId := utils.GenerateLogId(ctx)
 	logPrefix := fmt.Sprintf("[%s][HandlerVehicle][GetById]", logId)
+	actor := policy.NewActor(utils.GetAuthData(ctx))
 
 	vehicleId, err := utils.ValidateUUID(ctx, logId)
 	if err != nil {
 		return
 	}
 
-	data, err := h.Service.GetById(vehicleId)
+	data, err := h.Service.GetById(actor, vehicleId)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; GetById; Error: %+v", logPrefix, err))
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -143,15 +145,11 @@ func (h *HandlerVehicle) Fetch(ctx *gin.Context) {
 	params, _ := filter.GetBaseParams(ctx, "updated_at", "desc", 10)
 	params.Filters = filter.WhitelistFilter(params.Filters, []string{"user_id", "brand", "model", "year", "color"})
 
-	userId := utils.InterfaceString(authData["user_id"])
-	if utils.InterfaceString(authData["role"]) == utils.RoleCustomer {
-		params.Filters["user_id"] = userId
-	}
 	if params.Filters["year"] != nil {
 		params.Filters["year"] = strconv.Itoa(int(params.Filters["year"].(float64)))
 	}
 
-	vehicles, totalData, err := h.Service.Fetch(params)
+	vehicles, totalData, err := h.Service.Fetch(policy.NewActor(authData), params)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Fetch; Error: %+v", logPrefix, err))
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -189,8 +187,7 @@ func (h *HandlerVehicle) Fetch(ctx *gin.Context) {
 func (h *HandlerVehicle) Update(ctx *gin.Context) {
 	logId := utils.GenerateLogId(ctx)
 	logPrefix := fmt.Sprintf("[%s][HandlerVehicle][Update]", logId)
-	authData := utils.GetAuthData(ctx)
-	userId := utils.InterfaceString(authData["user_id"])
+	actor := policy.NewActor(utils.GetAuthData(ctx))
 
 	vehicleId, err := utils.ValidateUUID(ctx, logId)
 	if err != nil {
@@ -207,9 +204,15 @@ func (h *HandlerVehicle) Update(ctx *gin.Context) {
 	}
 	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
 
-	rows, err := h.Service.Update(vehicleId, userId, req)
+	rows, err := h.Service.WithContext(ctx).Update(actor, vehicleId, req)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Update; Error: %+v", logPrefix, err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+			res.Error = response.Errors{Code: http.StatusNotFound, Message: messages.NotFound}
+			ctx.JSON(http.StatusNotFound, res)
+			return
+		}
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
 			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; License plate: '%s' already exists", logPrefix, req.LicensePlate))
 			res := response.Response(http.StatusBadRequest, messages.MsgExists, logId, nil)
@@ -250,15 +253,14 @@ func (h *HandlerVehicle) Update(ctx *gin.Context) {
 func (h *HandlerVehicle) Delete(ctx *gin.Context) {
 	logId := utils.GenerateLogId(ctx)
 	logPrefix := fmt.Sprintf("[%s][HandlerVehicle][Delete]", logId)
-	authData := utils.GetAuthData(ctx)
-	userId := utils.InterfaceString(authData["user_id"])
+	actor := policy.NewActor(utils.GetAuthData(ctx))
 
 	vehicleId, err := utils.ValidateUUID(ctx, logId)
 	if err != nil {
 		return
 	}
 
-	if err = h.Service.Delete(vehicleId, userId); err != nil {
+	if err = h.Service.WithContext(ctx).Delete(actor, vehicleId); err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.Delete; Error: %+v", logPrefix, err))
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
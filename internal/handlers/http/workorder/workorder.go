@@ -14,6 +14,7 @@ import (
 	"workshop-management/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -43,11 +44,14 @@ func (h *HandlerWorkOrder) Fetch(ctx *gin.Context) {
 	authData := utils.GetAuthData(ctx)
 
 	params, _ := filter.GetBaseParams(ctx, "updated_at", "desc", 10)
-	params.Filters = filter.WhitelistFilter(params.Filters, []string{"status"})
+	params.Filters = filter.WhitelistFilter(params.Filters, []string{"status", "mechanic_id"})
 
 	userId := utils.InterfaceString(authData["user_id"])
-	if utils.InterfaceString(authData["role"]) == utils.RoleCustomer {
+	switch utils.InterfaceString(authData["role"]) {
+	case utils.RoleCustomer:
 		params.Filters["customer_id"] = userId
+	case utils.RoleMechanic:
+		params.Filters["mechanic_id"] = userId
 	}
 
 	data, totalData, err := h.Service.Fetch(params)
@@ -71,6 +75,42 @@ func (h *HandlerWorkOrder) Fetch(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
+// FetchAssigned godoc
+// @Summary Get the work orders assigned to the current mechanic
+// @Description Get the work orders whose mechanic is the authenticated user
+// @Tags Work Orders
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number for pagination"
+// @Param limit query int false "Number of items per page"
+// @Param filters[status] query string false "Filter by work order status"
+// @Success 200 {object} response.Success
+// @Failure 500 {object} response.Error
+// @Router /mechanic/workorders [get]
+// @Security Bearer
+func (h *HandlerWorkOrder) FetchAssigned(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][WorkOrderHandler][FetchAssigned]", logId)
+	authData := utils.GetAuthData(ctx)
+
+	params, _ := filter.GetBaseParams(ctx, "updated_at", "desc", 10)
+	params.Filters = filter.WhitelistFilter(params.Filters, []string{"status"})
+	params.Filters["mechanic_id"] = utils.InterfaceString(authData["user_id"])
+
+	data, totalData, err := h.Service.Fetch(params)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Fetch; Error: %+v", logPrefix, err))
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.PaginationResponse(http.StatusOK, int(totalData), params.Page, params.Limit, logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
 // CreateFromBooking godoc
 // @Summary Create a work order from a booking
 // @Description Create a new work order from an existing booking ID
@@ -94,7 +134,7 @@ func (h *HandlerWorkOrder) CreateFromBooking(ctx *gin.Context) {
 		return
 	}
 
-	data, err := h.Service.CreateFromBooking(bookingId, userId)
+	data, err := h.Service.WithContext(ctx).CreateFromBooking(bookingId, userId)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; CreateFromBooking; Error: %+v", logPrefix, err))
 		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
@@ -110,7 +150,7 @@ func (h *HandlerWorkOrder) CreateFromBooking(ctx *gin.Context) {
 
 // AssignMechanic godoc
 // @Summary Assign a mechanic to a work order
-// @Description Assign a mechanic to an existing work order
+// @Description Assign (or re-assign) a mechanic to an existing work order. The user must have the mechanic role and be below the active work order limit.
 // @Tags Work Orders
 // @Accept json
 // @Produce json
@@ -119,6 +159,8 @@ func (h *HandlerWorkOrder) CreateFromBooking(ctx *gin.Context) {
 // @Success 200 {object} response.Success
 // @Failure 400 {object} response.Error
 // @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 422 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /workorders/{id}/assign-mechanic [put]
 // @Security Bearer
@@ -142,12 +184,27 @@ func (h *HandlerWorkOrder) AssignMechanic(ctx *gin.Context) {
 	}
 	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
 
-	rows, err := h.Service.AssignMechanic(req, workOrderId, userId)
+	rows, err := h.Service.WithContext(ctx).AssignMechanic(req, workOrderId, userId)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.AssignMechanic; Error: %+v", logPrefix, err))
-		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
-		res.Error = err.Error()
-		ctx.JSON(http.StatusInternalServerError, res)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+			res.Error = response.Errors{Code: http.StatusNotFound, Message: messages.NotFound}
+			ctx.JSON(http.StatusNotFound, res)
+		case errors.Is(err, workorder.ErrInvalidMechanic):
+			res := response.Response(http.StatusUnprocessableEntity, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusUnprocessableEntity, Message: err.Error()}
+			ctx.JSON(http.StatusUnprocessableEntity, res)
+		case errors.Is(err, workorder.ErrAlreadyAssigned), errors.Is(err, workorder.ErrMechanicBusy), errors.Is(err, workorder.ErrNotAssignable):
+			res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+			ctx.JSON(http.StatusConflict, res)
+		default:
+			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+			res.Error = err.Error()
+			ctx.JSON(http.StatusInternalServerError, res)
+		}
 		return
 	}
 	if rows == 0 {
@@ -162,6 +219,95 @@ func (h *HandlerWorkOrder) AssignMechanic(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
+// GetAssignments godoc
+// @Summary Get the mechanic assignment history of a work order
+// @Description List every mechanic (re)assignment of a work order, oldest first
+// @Tags Work Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Work Order ID"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /workorder/{id}/assignments [get]
+// @Security Bearer
+func (h *HandlerWorkOrder) GetAssignments(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][WorkOrderHandler][GetAssignments]", logId)
+
+	workOrderId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	data, err := h.Service.GetAssignments(workOrderId)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; GetAssignments; Error: %+v", logPrefix, err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res := response.Response(http.StatusNotFound, messages.NotFound, logId, nil)
+			res.Error = "work order not found"
+			ctx.JSON(http.StatusNotFound, res)
+			return
+		}
+
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GetStatusHistory godoc
+// @Summary Get the status history of a work order
+// @Description List every status transition of a work order, oldest first. Customers only see their own work orders
+// @Tags Work Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Work Order ID"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /workorder/{id}/history [get]
+// @Security Bearer
+func (h *HandlerWorkOrder) GetStatusHistory(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][WorkOrderHandler][GetStatusHistory]", logId)
+	authData := utils.GetAuthData(ctx)
+	userId := utils.InterfaceString(authData["user_id"])
+	role := utils.InterfaceString(authData["role"])
+
+	workOrderId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	data, err := h.Service.GetStatusHistory(workOrderId, userId, role)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; GetStatusHistory; Error: %+v", logPrefix, err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res := response.Response(http.StatusNotFound, messages.NotFound, logId, nil)
+			res.Error = "work order not found"
+			ctx.JSON(http.StatusNotFound, res)
+			return
+		}
+
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
 // GetById godoc
 // @Summary Get a work order by ID
 // @Description Get a work order by ID
@@ -215,7 +361,9 @@ func (h *HandlerWorkOrder) GetById(ctx *gin.Context) {
 // @Param status body dto.UpdateStatus true "New status"
 // @Success 200 {object} response.Success
 // @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
 // @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /workorders/{id}/status [put]
 // @Security Bearer
@@ -224,6 +372,7 @@ func (h *HandlerWorkOrder) UpdateStatus(ctx *gin.Context) {
 	logPrefix := fmt.Sprintf("[%s][WorkOrderHandler][UpdateStatus]", logId)
 	authData := utils.GetAuthData(ctx)
 	userId := utils.InterfaceString(authData["user_id"])
+	role := utils.InterfaceString(authData["role"])
 
 	workOrderId, err := utils.ValidateUUID(ctx, logId)
 	if err != nil {
@@ -240,9 +389,189 @@ func (h *HandlerWorkOrder) UpdateStatus(ctx *gin.Context) {
 	}
 	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
 
-	rows, err := h.Service.UpdateStatus(workOrderId, req.Status, userId)
+	rows, err := h.Service.WithContext(ctx).UpdateStatus(req, workOrderId, userId, role)
 	if err != nil {
 		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.UpdateStatus; Error: %+v", logPrefix, err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+			res.Error = response.Errors{Code: http.StatusNotFound, Message: messages.NotFound}
+			ctx.JSON(http.StatusNotFound, res)
+			return
+		}
+
+		if errors.Is(err, workorder.ErrNotAssigned) {
+			res := response.Response(http.StatusForbidden, messages.MsgDenied, logId, nil)
+			res.Error = response.Errors{Code: http.StatusForbidden, Message: err.Error()}
+			ctx.JSON(http.StatusForbidden, res)
+			return
+		}
+
+		var transitionErr *workorder.TransitionError
+		if errors.As(err, &transitionErr) || errors.Is(err, workorder.ErrServicesPending) || errors.Is(err, workorder.ErrStatusChanged) {
+			res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+			ctx.JSON(http.StatusConflict, res)
+			return
+		}
+
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+	if rows == 0 {
+		res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+		res.Error = response.Errors{Code: http.StatusNotFound, Message: messages.NotFound}
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, nil)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(res)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// UpdateNotes godoc
+// @Summary Update the notes of a work order
+// @Description Update the notes of a work order. Mechanics can only update work orders assigned to them.
+// @Tags Work Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Work Order ID"
+// @Param notes body dto.UpdateWorkOrderNotes true "Notes"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /workorder/{id}/notes [put]
+// @Security Bearer
+func (h *HandlerWorkOrder) UpdateNotes(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][WorkOrderHandler][UpdateNotes]", logId)
+	authData := utils.GetAuthData(ctx)
+	userId := utils.InterfaceString(authData["user_id"])
+	role := utils.InterfaceString(authData["role"])
+
+	workOrderId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	var req dto.UpdateWorkOrderNotes
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
+
+	rows, err := h.Service.WithContext(ctx).UpdateNotes(req, workOrderId, userId, role)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.UpdateNotes; Error: %+v", logPrefix, err))
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+			res.Error = response.Errors{Code: http.StatusNotFound, Message: messages.NotFound}
+			ctx.JSON(http.StatusNotFound, res)
+		case errors.Is(err, workorder.ErrNotAssigned):
+			res := response.Response(http.StatusForbidden, messages.MsgDenied, logId, nil)
+			res.Error = response.Errors{Code: http.StatusForbidden, Message: err.Error()}
+			ctx.JSON(http.StatusForbidden, res)
+		case errors.Is(err, workorder.ErrNotEditable):
+			res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+			ctx.JSON(http.StatusConflict, res)
+		default:
+			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+			res.Error = err.Error()
+			ctx.JSON(http.StatusInternalServerError, res)
+		}
+		return
+	}
+	if rows == 0 {
+		res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+		res.Error = response.Errors{Code: http.StatusNotFound, Message: messages.NotFound}
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, nil)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(res)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// UpdateServiceStatus godoc
+// @Summary Update the status of a work order service line
+// @Description Mark an individual job of a work order as started, done or skipped
+// @Tags Work Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Work Order ID"
+// @Param svcId path string true "Work Order Service ID"
+// @Param status body dto.UpdateStatus true "New status (started, done, skipped)"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 403 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /workorder/{id}/services/{svcId}/status [put]
+// @Security Bearer
+func (h *HandlerWorkOrder) UpdateServiceStatus(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][WorkOrderHandler][UpdateServiceStatus]", logId)
+	authData := utils.GetAuthData(ctx)
+	userId := utils.InterfaceString(authData["user_id"])
+	role := utils.InterfaceString(authData["role"])
+
+	workOrderId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+	svcId, err := utils.ValidateUUIDParam(ctx, logId, "svcId")
+	if err != nil {
+		return
+	}
+
+	var req dto.UpdateStatus
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
+
+	rows, err := h.Service.WithContext(ctx).UpdateServiceStatus(workOrderId, svcId, req.Status, userId, role)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.UpdateServiceStatus; Error: %+v", logPrefix, err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+			res.Error = response.Errors{Code: http.StatusNotFound, Message: messages.NotFound}
+			ctx.JSON(http.StatusNotFound, res)
+			return
+		}
+
+		if errors.Is(err, workorder.ErrNotAssigned) {
+			res := response.Response(http.StatusForbidden, messages.MsgDenied, logId, nil)
+			res.Error = response.Errors{Code: http.StatusForbidden, Message: err.Error()}
+			ctx.JSON(http.StatusForbidden, res)
+			return
+		}
+
+		var transitionErr *workorder.TransitionError
+		if errors.As(err, &transitionErr) || errors.Is(err, workorder.ErrNotEditable) {
+			res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+			res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+			ctx.JSON(http.StatusConflict, res)
+			return
+		}
+
 		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
 		res.Error = err.Error()
 		ctx.JSON(http.StatusInternalServerError, res)
@@ -259,3 +588,162 @@ func (h *HandlerWorkOrder) UpdateStatus(ctx *gin.Context) {
 	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(res)))
 	ctx.JSON(http.StatusOK, res)
 }
+
+// AddPart godoc
+// @Summary Add a spare part to a work order
+// @Description Add a spare part line to a work order. The sparepart price is snapshotted and its stock is decremented.
+// @Tags Work Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Work Order ID"
+// @Param part body dto.AddWorkOrderPart true "Spare part and quantity"
+// @Success 201 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /workorder/{id}/parts [post]
+// @Security Bearer
+func (h *HandlerWorkOrder) AddPart(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][WorkOrderHandler][AddPart]", logId)
+	authData := utils.GetAuthData(ctx)
+	userId := utils.InterfaceString(authData["user_id"])
+
+	workOrderId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+
+	var req dto.AddWorkOrderPart
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
+
+	data, err := h.Service.WithContext(ctx).AddPart(req, workOrderId, userId)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.AddPart; Error: %+v", logPrefix, err))
+		h.partError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusCreated, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusCreated, res)
+}
+
+// UpdatePart godoc
+// @Summary Change the quantity of a work order part
+// @Description Change the quantity of a spare part line; stock is adjusted by the difference.
+// @Tags Work Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Work Order ID"
+// @Param partId path string true "Work Order Part ID"
+// @Param part body dto.UpdateWorkOrderPart true "New quantity"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /workorder/{id}/parts/{partId} [put]
+// @Security Bearer
+func (h *HandlerWorkOrder) UpdatePart(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][WorkOrderHandler][UpdatePart]", logId)
+	authData := utils.GetAuthData(ctx)
+	userId := utils.InterfaceString(authData["user_id"])
+
+	workOrderId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+	partId, err := utils.ValidateUUIDParam(ctx, logId, "partId")
+	if err != nil {
+		return
+	}
+
+	var req dto.UpdateWorkOrderPart
+	if err := ctx.BindJSON(&req); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; BindJSON ERROR: %s;", logPrefix, err.Error()))
+		res := response.Response(http.StatusBadRequest, messages.InvalidRequest, logId, nil)
+		res.Error = utils.ValidateError(err, reflect.TypeOf(req), "json")
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Request: %+v;", logPrefix, utils.JsonEncode(req)))
+
+	data, err := h.Service.WithContext(ctx).UpdatePart(req, workOrderId, partId, userId)
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.UpdatePart; Error: %+v", logPrefix, err))
+		h.partError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, "success", logId, data)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Response: %+v;", logPrefix, utils.JsonEncode(data)))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// RemovePart godoc
+// @Summary Remove a part from a work order
+// @Description Remove a spare part line from a work order and return its quantity to stock.
+// @Tags Work Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Work Order ID"
+// @Param partId path string true "Work Order Part ID"
+// @Success 200 {object} response.Success
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /workorder/{id}/parts/{partId} [delete]
+// @Security Bearer
+func (h *HandlerWorkOrder) RemovePart(ctx *gin.Context) {
+	logId := utils.GenerateLogId(ctx)
+	logPrefix := fmt.Sprintf("[%s][WorkOrderHandler][RemovePart]", logId)
+	authData := utils.GetAuthData(ctx)
+	userId := utils.InterfaceString(authData["user_id"])
+
+	workOrderId, err := utils.ValidateUUID(ctx, logId)
+	if err != nil {
+		return
+	}
+	partId, err := utils.ValidateUUIDParam(ctx, logId, "partId")
+	if err != nil {
+		return
+	}
+
+	if err = h.Service.WithContext(ctx).RemovePart(workOrderId, partId, userId); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Service.RemovePart; Error: %+v", logPrefix, err))
+		h.partError(ctx, logId, err)
+		return
+	}
+
+	res := response.Response(http.StatusOK, fmt.Sprintf("Part with ID: '%s' removed successfully", partId), logId, nil)
+	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("%s; Part with ID: '%s' removed successfully", logPrefix, partId))
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (h *HandlerWorkOrder) partError(ctx *gin.Context, logId uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		res := response.Response(http.StatusNotFound, messages.MsgNotFound, logId, nil)
+		res.Error = response.Errors{Code: http.StatusNotFound, Message: messages.NotFound}
+		ctx.JSON(http.StatusNotFound, res)
+	case errors.Is(err, workorder.ErrInsufficientStock), errors.Is(err, workorder.ErrNotEditable):
+		res := response.Response(http.StatusConflict, messages.MsgFail, logId, nil)
+		res.Error = response.Errors{Code: http.StatusConflict, Message: err.Error()}
+		ctx.JSON(http.StatusConflict, res)
+	default:
+		res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+		res.Error = err.Error()
+		ctx.JSON(http.StatusInternalServerError, res)
+	}
+}
@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"fmt"
+	"time"
+	"workshop-management/internal/domain/auth"
+	"workshop-management/pkg/logger"
+)
+
+// StartAuthCleanup periodically deletes expired blacklist entries, password reset tokens and sessions.
+// It runs in the background until the process exits; a non-positive interval disables it.
+func StartAuthCleanup(repo auth.RepoCleanup, interval time.Duration) {
+	if interval <= 0 {
+		logger.WriteLog(logger.LogLevelInfo, "[AuthCleanup]; disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			pruneExpired(repo)
+			<-ticker.C
+		}
+	}()
+}
+
+func pruneExpired(repo auth.RepoCleanup) {
+	total, err := repo.PruneExpired(time.Now().UTC())
+	if err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[AuthCleanup]; PruneExpired; Error: %+v", err))
+		return
+	}
+	logger.WriteLog(logger.LogLevelInfo, fmt.Sprintf("[AuthCleanup]; pruned %d expired rows", total))
+}
@@ -0,0 +1,17 @@
+package policy
+
+import (
+	"slices"
+	"strings"
+	"workshop-management/utils"
+)
+
+// MfaRequired reports whether users with the role must log in with a second factor, as configured by
+// MFA_REQUIRED_ROLES (comma separated, e.g. "admin,cashier"; empty by default).
+func MfaRequired(role string) bool {
+	roles := strings.Split(utils.GetEnv("MFA_REQUIRED_ROLES", "").(string), ",")
+	for i := range roles {
+		roles[i] = strings.TrimSpace(roles[i])
+	}
+	return role != "" && slices.Contains(roles, role)
+}
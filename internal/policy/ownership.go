@@ -0,0 +1,50 @@
+package policy
+
+import (
+	"slices"
+	"workshop-management/utils"
+
+	"gorm.io/gorm"
+)
+
+// staffRoles can access every customer-owned resource.
+var staffRoles = []string{utils.RoleAdmin, utils.RoleCashier, utils.RoleMechanic}
+
+// Actor is the authenticated caller a service acts on behalf of.
+type Actor struct {
+	UserId string
+	Role   string
+}
+
+func NewActor(authData map[string]interface{}) Actor {
+	return Actor{
+		UserId: utils.InterfaceString(authData["user_id"]),
+		Role:   utils.InterfaceString(authData["role"]),
+	}
+}
+
+func (a Actor) IsStaff() bool {
+	return slices.Contains(staffRoles, a.Role)
+}
+
+// CanAccess reports whether the actor may read or modify a resource owned by ownerId.
+func (a Actor) CanAccess(ownerId string) bool {
+	return a.IsStaff() || (a.UserId != "" && a.UserId == ownerId)
+}
+
+// Authorize returns gorm.ErrRecordNotFound for resources the actor does not own, so that foreign
+// resources are indistinguishable from missing ones.
+func (a Actor) Authorize(ownerId string) error {
+	if !a.CanAccess(ownerId) {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// OwnerScope returns the user id list queries must be restricted to, or "" when the actor is unrestricted.
+func (a Actor) OwnerScope() string {
+	if a.IsStaff() {
+		return ""
+	}
+	return a.UserId
+}
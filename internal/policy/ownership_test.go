@@ -0,0 +1,55 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+	"workshop-management/utils"
+
+	"gorm.io/gorm"
+)
+
+func TestActorAuthorize(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   Actor
+		ownerId string
+		wantErr error
+	}{
+		{"customer owns the resource", Actor{UserId: "u1", Role: utils.RoleCustomer}, "u1", nil},
+		{"customer on a foreign resource", Actor{UserId: "u1", Role: utils.RoleCustomer}, "u2", gorm.ErrRecordNotFound},
+		{"customer without id", Actor{Role: utils.RoleCustomer}, "", gorm.ErrRecordNotFound},
+		{"admin on a foreign resource", Actor{UserId: "a1", Role: utils.RoleAdmin}, "u2", nil},
+		{"cashier on a foreign resource", Actor{UserId: "c1", Role: utils.RoleCashier}, "u2", nil},
+		{"mechanic on a foreign resource", Actor{UserId: "m1", Role: utils.RoleMechanic}, "u2", nil},
+		{"unknown role on a foreign resource", Actor{UserId: "x1", Role: "guest"}, "u2", gorm.ErrRecordNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.actor.Authorize(tt.ownerId); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authorize(%q) = %v, want %v", tt.ownerId, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestActorOwnerScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		actor Actor
+		want  string
+	}{
+		{"customer is restricted to own rows", Actor{UserId: "u1", Role: utils.RoleCustomer}, "u1"},
+		{"admin is unrestricted", Actor{UserId: "a1", Role: utils.RoleAdmin}, ""},
+		{"cashier is unrestricted", Actor{UserId: "c1", Role: utils.RoleCashier}, ""},
+		{"mechanic is unrestricted", Actor{UserId: "m1", Role: utils.RoleMechanic}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.actor.OwnerScope(); got != tt.want {
+				t.Fatalf("OwnerScope() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
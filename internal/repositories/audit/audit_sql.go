@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"fmt"
+	"workshop-management/internal/domain/audit"
+	"workshop-management/pkg/filter"
+
+	"gorm.io/gorm"
+)
+
+type repo struct {
+	DB *gorm.DB
+}
+
+func NewAuditRepo(db *gorm.DB) audit.RepoAudit {
+	return &repo{DB: db}
+}
+
+func (r *repo) Fetch(params filter.BaseParams) (ret []audit.Event, totalData int64, err error) {
+	query := r.DB.Model(&audit.Event{})
+
+	for key, value := range params.Filters {
+		switch key {
+		case "from":
+			query = query.Where("created_at >= ?", value)
+		case "to":
+			query = query.Where("created_at < ?", value)
+		default:
+			if v, ok := value.(string); ok && v == "" {
+				continue
+			}
+			query = query.Where(fmt.Sprintf("%s = ?", key), value)
+		}
+	}
+
+	if err = query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	validColumns := map[string]bool{
+		"created_at": true,
+		"entity":     true,
+		"action":     true,
+	}
+	if !validColumns[params.OrderBy] {
+		return nil, 0, fmt.Errorf("invalid orderBy column: %s", params.OrderBy)
+	}
+
+	if err = query.Order(fmt.Sprintf("%s %s", params.OrderBy, params.OrderDirection)).
+		Offset(params.Offset).Limit(params.Limit).Find(&ret).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ret, totalData, nil
+}
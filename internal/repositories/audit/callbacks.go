@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+	"workshop-management/internal/domain/audit"
+	"workshop-management/utils"
+
+	"gorm.io/gorm"
+)
+
+const beforeKey = "audit:before"
+
+// redactedColumns are recorded as changed without their values.
+var redactedColumns = map[string]bool{
+	"password":    true,
+	"totp_secret": true,
+}
+
+// ignoredColumns change on every write and would only add noise to the diff.
+var ignoredColumns = map[string]bool{
+	"updated_at":     true,
+	"totp_last_step": true,
+}
+
+// Plugin records an audit.Event for every create, update and delete of the audited tables, inside the
+// transaction of the write itself. The actor and log id are taken from the statement context (see
+// audit.ActorFrom), falling back to the deleted_by/updated_by/created_by columns of the row.
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "audit"
+}
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_create", record(audit.ActionCreate)); err != nil {
+		return err
+	}
+
+	if err := cb.Update().After("gorm:begin_transaction").Before("gorm:update").
+		Register("audit:before_update", snapshotBefore); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_update", record(audit.ActionUpdate)); err != nil {
+		return err
+	}
+
+	if err := cb.Delete().After("gorm:begin_transaction").Before("gorm:delete").
+		Register("audit:before_delete", snapshotBefore); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_delete", record(audit.ActionDelete))
+}
+
+func audited(db *gorm.DB) bool {
+	_, ok := audit.Entities[db.Statement.Table]
+	return ok
+}
+
+// session returns a fresh query on the connection of the audited statement, so snapshots and events
+// share its transaction.
+func session(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+}
+
+// snapshotBefore loads the rows an update or delete is about to change.
+func snapshotBefore(db *gorm.DB) {
+	if db.Error != nil || !audited(db) {
+		return
+	}
+
+	query := session(db).Table(db.Statement.Table)
+	if ids := primaryKeys(db); len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	} else if where, ok := db.Statement.Clauses["WHERE"]; ok {
+		query = query.Clauses(where.Expression)
+	} else {
+		return
+	}
+
+	var rows []map[string]interface{}
+	if err := query.Find(&rows).Error; err != nil {
+		db.AddError(fmt.Errorf("audit snapshot: %w", err))
+		return
+	}
+	db.InstanceSet(beforeKey, rows)
+}
+
+func record(action string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || !audited(db) {
+			return
+		}
+
+		before := map[string]map[string]interface{}{}
+		var ids []interface{}
+		if action == audit.ActionCreate {
+			ids = primaryKeys(db)
+		} else if v, ok := db.InstanceGet(beforeKey); ok {
+			for _, row := range v.([]map[string]interface{}) {
+				id := utils.InterfaceString(row["id"])
+				before[id] = row
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			return
+		}
+
+		var rows []map[string]interface{}
+		if err := session(db).Table(db.Statement.Table).Where("id IN ?", ids).Find(&rows).Error; err != nil {
+			db.AddError(fmt.Errorf("audit snapshot: %w", err))
+			return
+		}
+		after := make(map[string]map[string]interface{}, len(rows))
+		for _, row := range rows {
+			after[utils.InterfaceString(row["id"])] = row
+		}
+
+		actor := audit.ActorFrom(db.Statement.Context)
+		now := time.Now()
+
+		var events []audit.Event
+		for _, id := range ids {
+			entityId := utils.InterfaceString(id)
+			event, ok := newEvent(action, before[entityId], after[entityId])
+			if !ok {
+				continue
+			}
+
+			event.Id = utils.CreateUUID()
+			event.LogId = actor.LogId
+			event.ActorId = actor.UserId
+			event.ActorRole = actor.Role
+			event.Entity = audit.Entities[db.Statement.Table]
+			event.EntityId = entityId
+			event.CreatedAt = now
+			if event.ActorId == "" {
+				event.ActorId = rowActor(after[entityId])
+			}
+			events = append(events, event)
+		}
+		if len(events) == 0 {
+			return
+		}
+
+		if err := session(db).Create(&events).Error; err != nil {
+			db.AddError(fmt.Errorf("audit record: %w", err))
+		}
+	}
+}
+
+// newEvent builds the event for one row, or reports false when nothing relevant changed.
+func newEvent(action string, before, after map[string]interface{}) (audit.Event, bool) {
+	switch {
+	case before == nil && after == nil:
+		return audit.Event{}, false
+	case before == nil:
+		return audit.Event{Action: audit.ActionCreate, After: encode(after)}, true
+	case after == nil:
+		return audit.Event{Action: audit.ActionDelete, Before: encode(before)}, true
+	}
+
+	oldValues, newValues := map[string]interface{}{}, map[string]interface{}{}
+	for column, value := range after {
+		if ignoredColumns[column] || sameValue(before[column], value) {
+			continue
+		}
+		oldValues[column], newValues[column] = before[column], value
+	}
+	if len(newValues) == 0 {
+		return audit.Event{}, false
+	}
+
+	// soft deletes are updates of deleted_at
+	if isNull(before["deleted_at"]) && !isNull(after["deleted_at"]) {
+		action = audit.ActionDelete
+	}
+
+	return audit.Event{Action: action, Before: encode(oldValues), After: encode(newValues)}, true
+}
+
+// rowActor returns the user recorded on the row by the last write.
+func rowActor(row map[string]interface{}) string {
+	for _, column := range []string{"deleted_by", "updated_by", "created_by"} {
+		if v := reflect.Indirect(reflect.ValueOf(row[column])); v.IsValid() && v.Kind() == reflect.String && v.String() != "" {
+			return v.String()
+		}
+	}
+	return ""
+}
+
+func encode(row map[string]interface{}) json.RawMessage {
+	values := make(map[string]interface{}, len(row))
+	for column, value := range row {
+		if redactedColumns[column] && !isNull(value) {
+			value = "[redacted]"
+		}
+		values[column] = value
+	}
+
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return json.RawMessage(fmt.Sprintf("%q", err.Error()))
+	}
+	return raw
+}
+
+func sameValue(a, b interface{}) bool {
+	rawA, errA := json.Marshal(a)
+	rawB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(rawA, rawB)
+}
+
+func isNull(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// primaryKeys returns the non-zero primary keys of the statement's model or of a map destination.
+func primaryKeys(db *gorm.DB) []interface{} {
+	stmt := db.Statement
+	if row, ok := stmt.Dest.(map[string]interface{}); ok {
+		if id, ok := row["id"]; ok {
+			return []interface{}{id}
+		}
+		return nil
+	}
+	if stmt.Schema == nil || stmt.Schema.PrioritizedPrimaryField == nil || !stmt.ReflectValue.IsValid() {
+		return nil
+	}
+
+	field := stmt.Schema.PrioritizedPrimaryField
+	var ids []interface{}
+	collect := func(rv reflect.Value) {
+		if v, zero := field.ValueOf(stmt.Context, reflect.Indirect(rv)); !zero {
+			ids = append(ids, v)
+		}
+	}
+
+	switch rv := reflect.Indirect(stmt.ReflectValue); rv.Kind() {
+	case reflect.Struct:
+		collect(rv)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			collect(rv.Index(i))
+		}
+	}
+	return ids
+}
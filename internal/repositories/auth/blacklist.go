@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"time"
 	"workshop-management/internal/domain/auth"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type blacklistRepo struct {
@@ -16,12 +18,63 @@ func NewBlacklistRepo(db *gorm.DB) auth.RepoAuth {
 	}
 }
 
+func NewCleanupRepo(db *gorm.DB) auth.RepoCleanup {
+	return &blacklistRepo{
+		DB: db,
+	}
+}
+
 func (r *blacklistRepo) Store(blacklist auth.Blacklist) error {
 	return r.DB.Create(&blacklist).Error
 }
 
-func (r *blacklistRepo) GetByToken(token string) (auth.Blacklist, error) {
-	var blacklist auth.Blacklist
-	err := r.DB.Where("token = ?", token).First(&blacklist).Error
-	return blacklist, err
+func (r *blacklistRepo) IsBlacklisted(jti string) (bool, error) {
+	var total int64
+	err := r.DB.Model(&auth.Blacklist{}).Where("jti = ?", jti).Count(&total).Error
+	return total > 0, err
+}
+
+// RevokeUser invalidates every token of the user issued before at.
+func (r *blacklistRepo) RevokeUser(userId string, at time.Time) error {
+	m := auth.Revocation{UserId: userId, RevokedAt: at}
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_at"}),
+	}).Create(&m).Error
+}
+
+// GetRevokedAt returns the zero time when the user's tokens were never revoked.
+func (r *blacklistRepo) GetRevokedAt(userId string) (time.Time, error) {
+	var m auth.Revocation
+	err := r.DB.Where("user_id = ?", userId).Limit(1).Find(&m).Error
+	return m.RevokedAt, err
+}
+
+// IsSessionRevoked reports whether the session was revoked, has expired or no longer exists.
+func (r *blacklistRepo) IsSessionRevoked(sessionId string, now time.Time) (bool, error) {
+	var total int64
+	err := r.DB.Model(&auth.Session{}).
+		Where("id = ? AND revoked_at IS NULL AND expires_at > ?", sessionId, now).
+		Count(&total).Error
+	return total == 0, err
+}
+
+// RevokeSessions has nothing to do: IsSessionRevoked reads the sessions table itself.
+func (r *blacklistRepo) RevokeSessions(sessions []auth.Session) error {
+	return nil
+}
+
+// PruneExpired deletes blacklist entries of expired tokens together with expired password reset tokens,
+// verification codes, two-factor challenges and sessions (their refresh tokens are removed by the
+// foreign key cascade).
+func (r *blacklistRepo) PruneExpired(now time.Time) (int64, error) {
+	var total int64
+	for _, model := range []interface{}{&auth.Blacklist{}, &auth.PasswordReset{}, &auth.VerificationCode{}, &auth.MfaChallenge{}, &auth.Session{}} {
+		res := r.DB.Where("expires_at < ?", now).Delete(model)
+		if res.Error != nil {
+			return total, res.Error
+		}
+		total += res.RowsAffected
+	}
+	return total, nil
 }
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+	"workshop-management/internal/domain/auth"
+	"workshop-management/utils"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisBlacklistKey      = "auth:blacklist:"
+	redisRevokedUserKey    = "auth:revoked:user:"
+	redisRevokedSessionKey = "auth:revoked:session:"
+)
+
+// redisBlacklistRepo keeps the token blacklist in Redis with keys expiring together with the tokens.
+// Sessions are persisted in the database; their revocations are mirrored into Redis, so authenticated
+// requests never have to query the database.
+type redisBlacklistRepo struct {
+	Client *redis.Client
+}
+
+func NewRedisBlacklistRepo(client *redis.Client) auth.RepoAuth {
+	return &redisBlacklistRepo{
+		Client: client,
+	}
+}
+
+func (r *redisBlacklistRepo) Store(m auth.Blacklist) error {
+	ttl := time.Until(m.ExpiresAt)
+	if ttl <= 0 {
+		return nil // already expired, nothing to block
+	}
+	return r.Client.Set(context.Background(), redisBlacklistKey+m.Jti, 1, ttl).Err()
+}
+
+func (r *redisBlacklistRepo) IsBlacklisted(jti string) (bool, error) {
+	total, err := r.Client.Exists(context.Background(), redisBlacklistKey+jti).Result()
+	return total > 0, err
+}
+
+// RevokeUser only needs to outlive the access tokens it invalidates.
+func (r *redisBlacklistRepo) RevokeUser(userId string, at time.Time) error {
+	return r.Client.Set(context.Background(), redisRevokedUserKey+userId, at.Unix(), utils.AccessTokenTTL()).Err()
+}
+
+func (r *redisBlacklistRepo) GetRevokedAt(userId string) (time.Time, error) {
+	v, err := r.Client.Get(context.Background(), redisRevokedUserKey+userId).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+
+	unix, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(unix, 0), nil
+}
+
+// IsSessionRevoked only sees revocations recorded through RevokeSessions. Expired sessions need no
+// entry, as the access tokens bound to them cannot be refreshed.
+func (r *redisBlacklistRepo) IsSessionRevoked(sessionId string, now time.Time) (bool, error) {
+	total, err := r.Client.Exists(context.Background(), redisRevokedSessionKey+sessionId).Result()
+	return total > 0, err
+}
+
+// RevokeSessions keeps each revocation until the session would have expired.
+func (r *redisBlacklistRepo) RevokeSessions(sessions []auth.Session) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	pipe := r.Client.Pipeline()
+	for _, session := range sessions {
+		if ttl := time.Until(session.ExpiresAt); ttl > 0 {
+			pipe.Set(context.Background(), redisRevokedSessionKey+session.Id, 1, ttl)
+		}
+	}
+	_, err := pipe.Exec(context.Background())
+	return err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"sync"
+	"time"
+	"workshop-management/internal/domain/auth"
+)
+
+type memoryAttempt struct {
+	auth.LoginAttempt
+	expiresAt time.Time
+}
+
+// memoryLoginAttemptRepo keeps failed-login counters in process memory. Counters are per instance and
+// lost on restart, so it is meant for single-instance deployments and local development.
+type memoryLoginAttemptRepo struct {
+	mu        sync.Mutex
+	entries   map[string]memoryAttempt
+	lastPrune time.Time
+}
+
+func NewMemoryLoginAttemptRepo() auth.RepoLoginAttempt {
+	return &memoryLoginAttemptRepo{entries: make(map[string]memoryAttempt)}
+}
+
+func (r *memoryLoginAttemptRepo) Get(key string, now time.Time) (auth.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		return auth.LoginAttempt{}, nil
+	}
+	return entry.LoginAttempt, nil
+}
+
+func (r *memoryLoginAttemptRepo) RegisterFailure(key string, policy auth.LockoutPolicy, now time.Time) (auth.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prune(now, policy.Window)
+
+	entry := r.entries[key]
+	if !now.Before(entry.expiresAt) {
+		entry = memoryAttempt{}
+	}
+
+	entry.Failures++
+	lock := policy.LockFor(entry.Failures)
+	if lock > 0 {
+		entry.LockedUntil = now.Add(lock)
+	}
+	entry.expiresAt = now.Add(policy.Window + lock)
+	r.entries[key] = entry
+
+	return entry.LoginAttempt, nil
+}
+
+func (r *memoryLoginAttemptRepo) Reset(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.entries, key)
+	return nil
+}
+
+// prune drops expired counters at most once per window, so keys sprayed by an attacker do not pile up.
+func (r *memoryLoginAttemptRepo) prune(now time.Time, every time.Duration) {
+	if now.Sub(r.lastPrune) < every {
+		return
+	}
+	for key, entry := range r.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(r.entries, key)
+		}
+	}
+	r.lastPrune = now
+}
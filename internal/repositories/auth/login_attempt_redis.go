@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"workshop-management/internal/domain/auth"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisLoginFailuresKey = "auth:login:failures:"
+	redisLoginLockKey     = "auth:login:lock:"
+)
+
+// redisLoginAttemptRepo shares failed-login counters between instances. The failure counter expires
+// Window after the last failure and the lock key expires when the lockout ends.
+type redisLoginAttemptRepo struct {
+	Client *redis.Client
+}
+
+func NewRedisLoginAttemptRepo(client *redis.Client) auth.RepoLoginAttempt {
+	return &redisLoginAttemptRepo{Client: client}
+}
+
+func (r *redisLoginAttemptRepo) Get(key string, now time.Time) (auth.LoginAttempt, error) {
+	ctx := context.Background()
+
+	pipe := r.Client.Pipeline()
+	failures := pipe.Get(ctx, redisLoginFailuresKey+key)
+	lockTTL := pipe.PTTL(ctx, redisLoginLockKey+key)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return auth.LoginAttempt{}, err
+	}
+
+	var ret auth.LoginAttempt
+	if n, err := failures.Int(); err == nil {
+		ret.Failures = n
+	}
+	if ttl := lockTTL.Val(); ttl > 0 {
+		ret.LockedUntil = now.Add(ttl)
+	}
+	return ret, nil
+}
+
+func (r *redisLoginAttemptRepo) RegisterFailure(key string, policy auth.LockoutPolicy, now time.Time) (auth.LoginAttempt, error) {
+	ctx := context.Background()
+
+	failures, err := r.Client.Incr(ctx, redisLoginFailuresKey+key).Result()
+	if err != nil {
+		return auth.LoginAttempt{}, err
+	}
+
+	ret := auth.LoginAttempt{Failures: int(failures)}
+	lock := policy.LockFor(ret.Failures)
+
+	pipe := r.Client.Pipeline()
+	if lock > 0 {
+		pipe.Set(ctx, redisLoginLockKey+key, 1, lock)
+		ret.LockedUntil = now.Add(lock)
+	}
+	pipe.Expire(ctx, redisLoginFailuresKey+key, policy.Window+lock)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return auth.LoginAttempt{}, err
+	}
+
+	return ret, nil
+}
+
+func (r *redisLoginAttemptRepo) Reset(key string) error {
+	return r.Client.Del(context.Background(), redisLoginFailuresKey+key, redisLoginLockKey+key).Err()
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+	"time"
+	"workshop-management/internal/domain/auth"
+
+	"gorm.io/gorm"
+)
+
+type mfaRepo struct {
+	DB *gorm.DB
+}
+
+func NewMfaRepo(db *gorm.DB) auth.RepoMfa {
+	return &mfaRepo{DB: db}
+}
+
+func (r *mfaRepo) StoreChallenge(m auth.MfaChallenge) error {
+	return r.DB.Create(&m).Error
+}
+
+// GetChallenge returns an unused, unexpired challenge without consuming it, so a mistyped code does not
+// force the user to enter their password again.
+func (r *mfaRepo) GetChallenge(tokenHash string, now time.Time) (auth.MfaChallenge, error) {
+	var m auth.MfaChallenge
+	err := r.DB.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return auth.MfaChallenge{}, auth.ErrInvalidMfaToken
+	}
+	return m, err
+}
+
+// ConsumeChallenge marks the challenge as used; the conditional update lets only one request win.
+func (r *mfaRepo) ConsumeChallenge(id string, now time.Time) error {
+	res := r.DB.Model(&auth.MfaChallenge{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", id, now).
+		Update("used_at", now)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return auth.ErrInvalidMfaToken
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes deletes every recovery code of the user and stores the new set.
+func (r *mfaRepo) ReplaceRecoveryCodes(userId string, codes []auth.RecoveryCode) error {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := tx.Where("user_id = ?", userId).Delete(&auth.RecoveryCode{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(codes) > 0 {
+		if err := tx.Create(&codes).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *mfaRepo) ConsumeRecoveryCode(userId, codeHash string, now time.Time) (bool, error) {
+	res := r.DB.Model(&auth.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", now)
+	return res.RowsAffected > 0, res.Error
+}
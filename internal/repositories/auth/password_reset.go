@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"errors"
+	"time"
+	"workshop-management/internal/domain/auth"
+
+	"gorm.io/gorm"
+)
+
+type passwordResetRepo struct {
+	DB *gorm.DB
+}
+
+func NewPasswordResetRepo(db *gorm.DB) auth.RepoPasswordReset {
+	return &passwordResetRepo{DB: db}
+}
+
+// Store saves a new reset token and invalidates any unused token previously issued to the user.
+func (r *passwordResetRepo) Store(m auth.PasswordReset) error {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := tx.Model(&auth.PasswordReset{}).
+		Where("user_id = ? AND used_at IS NULL", m.UserId).
+		Update("used_at", m.CreatedAt).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(&m).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// Consume marks an unused, unexpired token as used. The conditional update guarantees a token
+// can only be consumed once even under concurrent requests.
+func (r *passwordResetRepo) Consume(tokenHash string, now time.Time) (auth.PasswordReset, error) {
+	var m auth.PasswordReset
+	if err := r.DB.Where("token_hash = ?", tokenHash).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.PasswordReset{}, auth.ErrInvalidResetToken
+		}
+		return auth.PasswordReset{}, err
+	}
+
+	res := r.DB.Model(&auth.PasswordReset{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", m.Id, now).
+		Update("used_at", now)
+	if res.Error != nil {
+		return auth.PasswordReset{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return auth.PasswordReset{}, auth.ErrInvalidResetToken
+	}
+
+	m.UsedAt = &now
+	return m, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+	"time"
+	"workshop-management/internal/domain/auth"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type sessionRepo struct {
+	DB *gorm.DB
+}
+
+func NewSessionRepo(db *gorm.DB) auth.RepoSession {
+	return &sessionRepo{DB: db}
+}
+
+func (r *sessionRepo) Create(session auth.Session, token auth.RefreshToken) error {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := tx.Create(&session).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(&token).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// Rotate consumes a refresh token and stores next as its successor. Presenting a token that was
+// already consumed revokes the whole session (token family) and returns it with ErrRefreshTokenReused.
+func (r *sessionRepo) Rotate(tokenHash string, next auth.RefreshToken, now time.Time) (auth.Session, error) {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return auth.Session{}, tx.Error
+	}
+
+	var token auth.RefreshToken
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.Session{}, auth.ErrInvalidRefreshToken
+		}
+		return auth.Session{}, err
+	}
+
+	var session auth.Session
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", token.SessionId).First(&session).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.Session{}, auth.ErrInvalidRefreshToken
+		}
+		return auth.Session{}, err
+	}
+
+	if session.RevokedAt != nil || !session.ExpiresAt.After(now) {
+		tx.Rollback()
+		return auth.Session{}, auth.ErrInvalidRefreshToken
+	}
+
+	if token.UsedAt != nil {
+		if err := tx.Model(&auth.Session{}).Where("id = ?", session.Id).Update("revoked_at", now).Error; err != nil {
+			tx.Rollback()
+			return auth.Session{}, err
+		}
+		if err := tx.Commit().Error; err != nil {
+			return auth.Session{}, err
+		}
+		session.RevokedAt = &now
+		return session, auth.ErrRefreshTokenReused
+	}
+
+	if err := tx.Model(&auth.RefreshToken{}).Where("id = ?", token.Id).Update("used_at", now).Error; err != nil {
+		tx.Rollback()
+		return auth.Session{}, err
+	}
+
+	next.SessionId = session.Id
+	if err := tx.Create(&next).Error; err != nil {
+		tx.Rollback()
+		return auth.Session{}, err
+	}
+
+	if err := tx.Model(&auth.Session{}).Where("id = ?", session.Id).Update("last_used_at", now).Error; err != nil {
+		tx.Rollback()
+		return auth.Session{}, err
+	}
+	session.LastUsedAt = now
+
+	return session, tx.Commit().Error
+}
+
+func (r *sessionRepo) GetActiveByUserId(userId string, now time.Time) ([]auth.Session, error) {
+	sessions := make([]auth.Session, 0)
+	err := r.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, now).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Revoke revokes one session of the user and returns it, or nothing when it was not found or already revoked.
+func (r *sessionRepo) Revoke(userId, sessionId string, now time.Time) ([]auth.Session, error) {
+	revoked := make([]auth.Session, 0)
+	err := r.DB.Model(&revoked).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionId, userId).
+		Update("revoked_at", now).Error
+	return revoked, err
+}
+
+// RevokeAll revokes every session of the user except exceptSessionId (pass "" to revoke all) and returns
+// the sessions it revoked.
+func (r *sessionRepo) RevokeAll(userId, exceptSessionId string, now time.Time) ([]auth.Session, error) {
+	revoked := make([]auth.Session, 0)
+	query := r.DB.Model(&revoked).Clauses(clause.Returning{}).Where("user_id = ? AND revoked_at IS NULL", userId)
+	if exceptSessionId != "" {
+		query = query.Where("id <> ?", exceptSessionId)
+	}
+	return revoked, query.Update("revoked_at", now).Error
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"crypto/subtle"
+	"errors"
+	"time"
+	"workshop-management/internal/domain/auth"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type verificationRepo struct {
+	DB *gorm.DB
+}
+
+func NewVerificationRepo(db *gorm.DB) auth.RepoVerification {
+	return &verificationRepo{DB: db}
+}
+
+// Store saves a new code and invalidates any unused code previously issued to the user.
+func (r *verificationRepo) Store(m auth.VerificationCode) error {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := tx.Model(&auth.VerificationCode{}).
+		Where("user_id = ? AND used_at IS NULL", m.UserId).
+		Update("used_at", m.CreatedAt).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(&m).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *verificationRepo) GetLatest(userId string) (ret auth.VerificationCode, err error) {
+	err = r.DB.Where("user_id = ?", userId).Order("created_at DESC").First(&ret).Error
+	return ret, err
+}
+
+// Consume checks codeHash against the user's active code. A wrong guess uses up one of maxAttempts;
+// a match marks the code as used so it cannot be replayed.
+func (r *verificationRepo) Consume(userId, codeHash string, maxAttempts int, now time.Time) (auth.VerificationCode, error) {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return auth.VerificationCode{}, tx.Error
+	}
+
+	var m auth.VerificationCode
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND used_at IS NULL AND expires_at > ? AND attempts < ?", userId, now, maxAttempts).
+		Order("created_at DESC").
+		First(&m).Error
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.VerificationCode{}, auth.ErrInvalidVerificationCode
+		}
+		return auth.VerificationCode{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(m.CodeHash), []byte(codeHash)) != 1 {
+		if err = tx.Model(&m).Update("attempts", gorm.Expr("attempts + 1")).Error; err != nil {
+			tx.Rollback()
+			return auth.VerificationCode{}, err
+		}
+		if err = tx.Commit().Error; err != nil {
+			return auth.VerificationCode{}, err
+		}
+		return auth.VerificationCode{}, auth.ErrInvalidVerificationCode
+	}
+
+	if err = tx.Model(&m).Update("used_at", now).Error; err != nil {
+		tx.Rollback()
+		return auth.VerificationCode{}, err
+	}
+	if err = tx.Commit().Error; err != nil {
+		return auth.VerificationCode{}, err
+	}
+
+	m.UsedAt = &now
+	return m, nil
+}
@@ -1,12 +1,16 @@
 package booking
 
 import (
+	"context"
 	"fmt"
+	"time"
 	"workshop-management/internal/domain/booking"
 	"workshop-management/internal/domain/service"
 	"workshop-management/pkg/filter"
+	"workshop-management/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type repo struct {
@@ -17,13 +21,34 @@ func NewBookingRepo(db *gorm.DB) booking.RepoBooking {
 	return &repo{DB: db}
 }
 
-func (r *repo) Create(booking booking.Booking, bookingServices []booking.BookService) error {
+func (r *repo) WithContext(ctx context.Context) booking.RepoBooking {
+	return &repo{DB: r.DB.WithContext(ctx)}
+}
+
+// Create stores the booking and its services, refusing it with ErrSlotFull when the slot already holds
+// slot.Capacity active bookings. Concurrent bookings of the same slot are serialised with an advisory lock.
+func (r *repo) Create(m booking.Booking, bookingServices []booking.BookService, slot booking.Slot, history booking.StatusHistory) error {
 	tx := r.DB.Begin()
 	if tx.Error != nil {
 		return tx.Error
 	}
 
-	if err := tx.Omit("Services").Create(&booking).Error; err != nil {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "booking_slot:"+slot.Start.UTC().Format(time.RFC3339)).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	booked, err := countActiveBetween(tx, slot.Start, slot.End, "")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if booked >= int64(slot.Capacity) {
+		tx.Rollback()
+		return booking.ErrSlotFull
+	}
+
+	if err := tx.Omit("Services").Create(&m).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -35,9 +60,32 @@ func (r *repo) Create(booking booking.Booking, bookingServices []booking.BookSer
 		}
 	}
 
+	if err := tx.Create(&history).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit().Error
 }
 
+func (r *repo) CountActiveBetween(start, end time.Time) (int64, error) {
+	return countActiveBetween(r.DB, start, end, "")
+}
+
+// countActiveBetween counts the bookings in [start, end) that still hold a bay, leaving out excludeId.
+func countActiveBetween(db *gorm.DB, start, end time.Time, excludeId string) (int64, error) {
+	query := db.Model(&booking.Booking{}).
+		Where("booking_date >= ? AND booking_date < ?", start, end).
+		Where("status <> ?", utils.StsCancelled)
+	if excludeId != "" {
+		query = query.Where("id <> ?", excludeId)
+	}
+
+	var total int64
+	err := query.Count(&total).Error
+	return total, err
+}
+
 func (r *repo) GetServicesByIDs(serviceIDs []string) ([]service.Service, error) {
 	var services []service.Service
 	if err := r.DB.Where("id IN ?", serviceIDs).Find(&services).Error; err != nil {
@@ -48,7 +96,7 @@ func (r *repo) GetServicesByIDs(serviceIDs []string) ([]service.Service, error)
 
 func (r *repo) GetById(id string) (booking.Booking, error) {
 	var m booking.Booking
-	if err := r.DB.Preload("Services").Where("id = ?", id).First(&m).Debug().Error; err != nil {
+	if err := r.DB.Preload("Services").Preload("Quote.Items").Where("id = ?", id).First(&m).Debug().Error; err != nil {
 		return booking.Booking{}, err
 	}
 	return m, nil
@@ -144,3 +192,203 @@ func (r *repo) Update(m booking.Booking, data interface{}) (int64, error) {
 	}
 	return res.RowsAffected, nil
 }
+
+// UpdateStatus locks the booking, applies data and records the transition from the locked status. The
+// change is rejected when the booking is already completed or cancelled, or when history.FromStatus is
+// set and the booking no longer has that status.
+func (r *repo) UpdateStatus(m booking.Booking, data map[string]interface{}, history booking.StatusHistory) (int64, error) {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+
+	var current booking.Booking
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").Where("id = ?", m.Id).First(&current).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if current.Status == utils.StsCompleted || current.Status == utils.StsCancelled ||
+		(history.FromStatus != nil && *history.FromStatus != current.Status) {
+		tx.Rollback()
+		return 0, booking.ErrStatusChanged
+	}
+
+	res := tx.Model(&current).Updates(data)
+	if res.Error != nil {
+		tx.Rollback()
+		return 0, res.Error
+	}
+
+	history.FromStatus = &current.Status
+	if err := tx.Create(&history).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected, nil
+}
+
+func (r *repo) GetStatusHistory(bookingId string) ([]booking.StatusHistory, error) {
+	var ret []booking.StatusHistory
+	if err := r.DB.Where("booking_id = ?", bookingId).Order("changed_at asc").Find(&ret).Error; err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// UpdateDetails applies data to a pending booking and, when serviceIDs is not nil, replaces its services.
+// When the booking moves to slot, the slot is re-checked under the same advisory lock as Create, without
+// counting the booking itself. A nil slot keeps the booking where it is.
+func (r *repo) UpdateDetails(m booking.Booking, data map[string]interface{}, serviceIDs []string, slot *booking.Slot) error {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	var current booking.Booking
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").Where("id = ?", m.Id).First(&current).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if current.Status != utils.StsPending {
+		tx.Rollback()
+		return booking.ErrNotEditable
+	}
+
+	if slot != nil {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "booking_slot:"+slot.Start.UTC().Format(time.RFC3339)).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		booked, err := countActiveBetween(tx, slot.Start, slot.End, m.Id)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if booked >= int64(slot.Capacity) {
+			tx.Rollback()
+			return booking.ErrSlotFull
+		}
+	}
+
+	if err := tx.Model(&current).Updates(data).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if serviceIDs != nil {
+		if err := syncServices(tx, m.Id, serviceIDs); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// syncServices makes the booking_services rows of a booking match serviceIDs, leaving unchanged rows alone.
+func syncServices(tx *gorm.DB, bookingId string, serviceIDs []string) error {
+	var current []booking.BookService
+	if err := tx.Where("booking_id = ?", bookingId).Find(&current).Error; err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool, len(serviceIDs))
+	for _, id := range serviceIDs {
+		wanted[id] = true
+	}
+
+	existing := make(map[string]bool, len(current))
+	var removed []string
+	for _, bs := range current {
+		existing[bs.ServiceID] = true
+		if !wanted[bs.ServiceID] {
+			removed = append(removed, bs.Id)
+		}
+	}
+
+	var added []booking.BookService
+	for _, id := range serviceIDs {
+		if existing[id] {
+			continue
+		}
+		existing[id] = true
+		added = append(added, booking.BookService{
+			Id:        utils.CreateUUID(),
+			BookingID: bookingId,
+			ServiceID: id,
+		})
+	}
+
+	if len(removed) > 0 {
+		if err := tx.Where("id IN ?", removed).Delete(&booking.BookService{}).Error; err != nil {
+			return err
+		}
+	}
+	if len(added) > 0 {
+		if err := tx.Create(&added).Error; err != nil {
+			return err
+		}
+	}
+
+	// an accepted quote no longer matches the booking once its services change
+	if len(removed) > 0 || len(added) > 0 {
+		if err := tx.Where("booking_id = ?", bookingId).Delete(&booking.Quote{}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SaveQuote stores the accepted quote of a pending booking, replacing any earlier one.
+func (r *repo) SaveQuote(quote booking.Quote) error {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	var current booking.Booking
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").Where("id = ?", quote.BookingId).First(&current).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if current.Status != utils.StsPending {
+		tx.Rollback()
+		return booking.ErrNotEditable
+	}
+
+	if err := tx.Where("booking_id = ?", quote.BookingId).Delete(&booking.Quote{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Omit("Items").Create(&quote).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(quote.Items) > 0 {
+		if err := tx.Create(&quote.Items).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *repo) GetQuote(bookingId string) (booking.Quote, error) {
+	var ret booking.Quote
+	if err := r.DB.Preload("Items").Where("booking_id = ?", bookingId).First(&ret).Error; err != nil {
+		return booking.Quote{}, err
+	}
+
+	return ret, nil
+}
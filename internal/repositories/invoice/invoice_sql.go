@@ -0,0 +1,109 @@
+package invoice
+
+import (
+	"fmt"
+	"workshop-management/internal/domain/invoice"
+	"workshop-management/pkg/filter"
+
+	"gorm.io/gorm"
+)
+
+type repo struct {
+	DB *gorm.DB
+}
+
+func NewInvoiceRepo(db *gorm.DB) invoice.RepoInvoice {
+	return &repo{DB: db}
+}
+
+func (r *repo) Create(m invoice.Invoice, items []invoice.Item) error {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := tx.Omit("Items").Create(&m).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(items) > 0 {
+		if err := tx.Create(&items).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *repo) GetById(id string) (invoice.Invoice, error) {
+	var m invoice.Invoice
+	if err := r.DB.Preload("Items").Where("id = ?", id).First(&m).Error; err != nil {
+		return invoice.Invoice{}, err
+	}
+	return m, nil
+}
+
+func (r *repo) GetByWorkOrderId(workOrderId string) (invoice.Invoice, error) {
+	var m invoice.Invoice
+	if err := r.DB.Preload("Items").Where("work_order_id = ?", workOrderId).First(&m).Error; err != nil {
+		return invoice.Invoice{}, err
+	}
+	return m, nil
+}
+
+func (r *repo) Fetch(params filter.BaseParams) (ret []invoice.Invoice, totalData int64, err error) {
+	query := r.DB.Model(&invoice.Invoice{}).Debug()
+
+	for key, value := range params.Filters {
+		if value == nil {
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			if v == "" {
+				continue
+			}
+			query = query.Where(fmt.Sprintf("%s = ?", key), v)
+		case []string, []int:
+			query = query.Where(fmt.Sprintf("%s IN ?", key), v)
+		default:
+			query = query.Where(fmt.Sprintf("%s = ?", key), v)
+		}
+	}
+
+	if err = query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if params.OrderBy != "" && params.OrderDirection != "" {
+		validColumns := map[string]bool{
+			"total":      true,
+			"status":     true,
+			"created_at": true,
+			"updated_at": true,
+		}
+
+		if _, ok := validColumns[params.OrderBy]; !ok {
+			return nil, 0, fmt.Errorf("invalid orderBy column: %s", params.OrderBy)
+		}
+
+		query = query.Order(fmt.Sprintf("%s %s", params.OrderBy, params.OrderDirection))
+	}
+
+	if err = query.Offset(params.Offset).Limit(params.Limit).Find(&ret).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ret, totalData, nil
+}
+
+func (r *repo) Update(m invoice.Invoice, data map[string]interface{}) (int64, error) {
+	res := r.DB.Model(&m).Where("id = ?", m.Id).Updates(data)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	return res.RowsAffected, nil
+}
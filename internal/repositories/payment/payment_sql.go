@@ -0,0 +1,89 @@
+package payment
+
+import (
+	"workshop-management/internal/domain/invoice"
+	"workshop-management/internal/domain/payment"
+	"workshop-management/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type repo struct {
+	DB *gorm.DB
+}
+
+func NewPaymentRepo(db *gorm.DB) payment.RepoPayment {
+	return &repo{DB: db}
+}
+
+func (r *repo) Create(m payment.Payment) (invoice.Invoice, error) {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return invoice.Invoice{}, tx.Error
+	}
+
+	// lock the invoice so concurrent payments are summed one after another
+	var inv invoice.Invoice
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", m.InvoiceId).First(&inv).Error; err != nil {
+		tx.Rollback()
+		return invoice.Invoice{}, err
+	}
+
+	if inv.Status == utils.StsPaid || inv.Status == utils.StsCancelled {
+		tx.Rollback()
+		return invoice.Invoice{}, payment.ErrInvoiceClosed
+	}
+
+	var paid float64
+	if err := tx.Model(&payment.Payment{}).Where("invoice_id = ?", inv.Id).Select("COALESCE(SUM(amount), 0)").Scan(&paid).Error; err != nil {
+		tx.Rollback()
+		return invoice.Invoice{}, err
+	}
+
+	if payment.Cents(paid)+payment.Cents(m.Amount) > payment.Cents(inv.Total) {
+		tx.Rollback()
+		return invoice.Invoice{}, payment.ErrOverpayment
+	}
+
+	if err := tx.Create(&m).Error; err != nil {
+		tx.Rollback()
+		return invoice.Invoice{}, err
+	}
+
+	status := payment.InvoiceStatus(inv.Total, paid+m.Amount)
+	data := map[string]interface{}{
+		"status":     status,
+		"updated_at": m.CreatedAt,
+		"updated_by": m.CreatedBy,
+	}
+	if err := tx.Model(&invoice.Invoice{}).Where("id = ?", inv.Id).Updates(data).Error; err != nil {
+		tx.Rollback()
+		return invoice.Invoice{}, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return invoice.Invoice{}, err
+	}
+	inv.Status = status
+	inv.UpdatedAt = m.CreatedAt
+	inv.UpdatedBy = m.CreatedBy
+
+	return inv, nil
+}
+
+func (r *repo) GetById(id string) (payment.Payment, error) {
+	var m payment.Payment
+	if err := r.DB.Where("id = ?", id).First(&m).Error; err != nil {
+		return payment.Payment{}, err
+	}
+	return m, nil
+}
+
+func (r *repo) GetByInvoiceId(invoiceId string) ([]payment.Payment, error) {
+	var ret []payment.Payment
+	if err := r.DB.Where("invoice_id = ?", invoiceId).Order("paid_at asc").Find(&ret).Error; err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
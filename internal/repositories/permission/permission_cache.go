@@ -0,0 +1,62 @@
+package permission
+
+import (
+	"sync"
+	"time"
+	"workshop-management/internal/domain/permission"
+)
+
+// cachedRepo keeps the whole role→permission mapping in memory for ttl, so RequirePermission does not
+// query the database on every request. Writes made through this instance invalidate the cache at once;
+// other instances pick them up when their ttl expires.
+type cachedRepo struct {
+	permission.RepoPermission
+	ttl time.Duration
+
+	mu       sync.RWMutex
+	grants   map[string]map[string]bool
+	loadedAt time.Time
+}
+
+func NewCachedPermissionRepo(repo permission.RepoPermission, ttl time.Duration) permission.RepoPermission {
+	return &cachedRepo{RepoPermission: repo, ttl: ttl}
+}
+
+func (r *cachedRepo) HasPermission(role, name string) (bool, error) {
+	r.mu.RLock()
+	if r.grants != nil && time.Since(r.loadedAt) < r.ttl {
+		granted := r.grants[role][name]
+		r.mu.RUnlock()
+		return granted, nil
+	}
+	r.mu.RUnlock()
+
+	rows, err := r.RepoPermission.GetAll()
+	if err != nil {
+		return false, err
+	}
+
+	grants := make(map[string]map[string]bool)
+	for _, row := range rows {
+		if grants[row.Role] == nil {
+			grants[row.Role] = make(map[string]bool)
+		}
+		grants[row.Role][row.Permission] = true
+	}
+
+	r.mu.Lock()
+	r.grants, r.loadedAt = grants, time.Now()
+	r.mu.Unlock()
+
+	return grants[role][name], nil
+}
+
+func (r *cachedRepo) ReplaceRole(role string, permissions []string, userId string) error {
+	err := r.RepoPermission.ReplaceRole(role, permissions, userId)
+
+	r.mu.Lock()
+	r.grants = nil
+	r.mu.Unlock()
+
+	return err
+}
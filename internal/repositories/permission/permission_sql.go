@@ -0,0 +1,56 @@
+package permission
+
+import (
+	"time"
+	"workshop-management/internal/domain/permission"
+
+	"gorm.io/gorm"
+)
+
+type repo struct {
+	DB *gorm.DB
+}
+
+func NewPermissionRepo(db *gorm.DB) permission.RepoPermission {
+	return &repo{DB: db}
+}
+
+func (r *repo) GetAll() (ret []permission.RolePermission, err error) {
+	err = r.DB.Order("role, permission").Find(&ret).Error
+	return ret, err
+}
+
+func (r *repo) HasPermission(role, name string) (bool, error) {
+	var count int64
+	err := r.DB.Model(&permission.RolePermission{}).
+		Where("role = ? AND permission = ?", role, name).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ReplaceRole swaps the whole permission set of a role in one transaction.
+func (r *repo) ReplaceRole(role string, permissions []string, userId string) error {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := tx.Where("role = ?", role).Delete(&permission.RolePermission{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(permissions) > 0 {
+		now := time.Now()
+		rows := make([]permission.RolePermission, 0, len(permissions))
+		for _, name := range permissions {
+			rows = append(rows, permission.RolePermission{Role: role, Permission: name, CreatedAt: now, CreatedBy: userId})
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"workshop-management/internal/domain/service"
 	"workshop-management/pkg/filter"
@@ -16,6 +17,10 @@ func NewServiceRepo(db *gorm.DB) service.RepoService {
 	return &repo{DB: db}
 }
 
+func (r *repo) WithContext(ctx context.Context) service.RepoService {
+	return &repo{DB: r.DB.WithContext(ctx)}
+}
+
 func (r *repo) Store(m service.Service) error {
 	return r.DB.Create(&m).Error
 }
@@ -0,0 +1,106 @@
+package sparepart
+
+import (
+	"fmt"
+	"workshop-management/internal/domain/sparepart"
+	"workshop-management/pkg/filter"
+
+	"gorm.io/gorm"
+)
+
+type repo struct {
+	DB *gorm.DB
+}
+
+func NewSparepartRepo(db *gorm.DB) sparepart.RepoSparepart {
+	return &repo{DB: db}
+}
+
+func (r *repo) Store(m sparepart.Sparepart) error {
+	return r.DB.Create(&m).Error
+}
+
+func (r *repo) Fetch(params filter.BaseParams) (ret []sparepart.Sparepart, totalData int64, err error) {
+	query := r.DB.Model(&sparepart.Sparepart{}).Debug()
+
+	if len(params.Columns) > 0 {
+		query = query.Select(params.Columns)
+	}
+
+	if params.Search != "" {
+		searchPattern := "%" + params.Search + "%"
+		query = query.Where("LOWER(code) LIKE LOWER(?) OR LOWER(name) LIKE LOWER(?)", searchPattern, searchPattern)
+	}
+
+	for key, value := range params.Filters {
+		if value == nil {
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			if v == "" {
+				continue
+			}
+			query = query.Where(fmt.Sprintf("%s = ?", key), v)
+		case []string, []int:
+			query = query.Where(fmt.Sprintf("%s IN ?", key), v)
+		default:
+			query = query.Where(fmt.Sprintf("%s = ?", key), v)
+		}
+	}
+
+	if err = query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if params.OrderBy != "" && params.OrderDirection != "" {
+		validColumns := map[string]bool{
+			"code":       true,
+			"name":       true,
+			"stock":      true,
+			"price":      true,
+			"created_at": true,
+			"updated_at": true,
+		}
+
+		if _, ok := validColumns[params.OrderBy]; !ok {
+			return nil, 0, fmt.Errorf("invalid orderBy column: %s", params.OrderBy)
+		}
+
+		query = query.Order(fmt.Sprintf("%s %s", params.OrderBy, params.OrderDirection))
+	}
+
+	if err := query.Offset(params.Offset).Limit(params.Limit).Find(&ret).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return ret, totalData, nil
+}
+
+func (r *repo) GetById(id string) (sparepart.Sparepart, error) {
+	var m sparepart.Sparepart
+	if err := r.DB.Where("id = ?", id).First(&m).Error; err != nil {
+		return sparepart.Sparepart{}, err
+	}
+	return m, nil
+}
+
+func (r *repo) Update(m sparepart.Sparepart, data interface{}) (int64, error) {
+	res := r.DB.Model(&m).Where("id = ?", m.Id).Updates(data)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	return res.RowsAffected, nil
+}
+
+func (r *repo) Delete(m sparepart.Sparepart, data interface{}) error {
+	res := r.DB.Model(&m).Where("id = ?", m.Id).Updates(data)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"workshop-management/internal/domain/user"
 	"workshop-management/pkg/filter"
@@ -16,6 +17,10 @@ func NewUserRepo(db *gorm.DB) user.RepoUser {
 	return &repo{DB: db}
 }
 
+func (r *repo) WithContext(ctx context.Context) user.RepoUser {
+	return &repo{DB: r.DB.WithContext(ctx)}
+}
+
 func (r *repo) Store(m user.Users) error {
 	return r.DB.Create(&m).Error
 }
@@ -93,6 +98,15 @@ func (r *repo) Update(m user.Users) error {
 	return r.DB.Save(&m).Error
 }
 
+// ClaimTotpStep records step as the last accepted TOTP step of the user. The conditional update lets only
+// one request use a given code; it reports false when a later or equal step was already recorded.
+func (r *repo) ClaimTotpStep(id string, step int64) (bool, error) {
+	res := r.DB.Model(&user.Users{}).
+		Where("id = ? AND (totp_last_step IS NULL OR totp_last_step < ?)", id, step).
+		Update("totp_last_step", step)
+	return res.RowsAffected > 0, res.Error
+}
+
 func (r *repo) Delete(id string) error {
 	return r.DB.Where("id = ?", id).Delete(&user.Users{}).Error
 }
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"workshop-management/internal/domain/vehicle"
 	"workshop-management/pkg/filter"
@@ -16,6 +17,10 @@ func NewVehicleRepo(db *gorm.DB) vehicle.RepoVehicle {
 	return &repo{DB: db}
 }
 
+func (r *repo) WithContext(ctx context.Context) vehicle.RepoVehicle {
+	return &repo{DB: r.DB.WithContext(ctx)}
+}
+
 func (r *repo) Store(m vehicle.Vehicle) error {
 	return r.DB.Create(&m).Error
 }
@@ -1,11 +1,16 @@
 package workorder
 
 import (
+	"context"
 	"fmt"
+	"time"
+	"workshop-management/internal/domain/sparepart"
 	"workshop-management/internal/domain/workorder"
 	"workshop-management/pkg/filter"
+	"workshop-management/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type repo struct {
@@ -16,7 +21,11 @@ func NewWorkOrderRepo(db *gorm.DB) workorder.RepoWorkOrder {
 	return &repo{DB: db}
 }
 
-func (r *repo) Create(workOrder workorder.WorkOrder, svcWorkOrders []workorder.SvcWorkOrder) error {
+func (r *repo) WithContext(ctx context.Context) workorder.RepoWorkOrder {
+	return &repo{DB: r.DB.WithContext(ctx)}
+}
+
+func (r *repo) Create(workOrder workorder.WorkOrder, svcWorkOrders []workorder.SvcWorkOrder, history workorder.StatusHistory) error {
 	tx := r.DB.Begin()
 	if tx.Error != nil {
 		return tx.Error
@@ -34,12 +43,23 @@ func (r *repo) Create(workOrder workorder.WorkOrder, svcWorkOrders []workorder.S
 		}
 	}
 
+	if err := tx.Create(&history).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit().Error
 }
 
 func (r *repo) GetById(id string) (workorder.WorkOrder, error) {
 	var wo workorder.WorkOrder
-	if err := r.DB.Where("id = ?", id).First(&wo).Error; err != nil {
+	query := r.DB.Preload("Services", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, work_order_id, service_id, service_name, price, quantity, status")
+	}).Preload("Parts").Preload("Parts.Sparepart", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped().Select("id, code, name")
+	})
+
+	if err := query.Where("id = ?", id).First(&wo).Error; err != nil {
 		return workorder.WorkOrder{}, err
 	}
 
@@ -111,3 +131,351 @@ func (r *repo) Fetch(params filter.BaseParams) (ret []workorder.WorkOrder, total
 
 	return ret, totalData, nil
 }
+
+func (r *repo) UpdateStatus(m workorder.WorkOrder, data map[string]interface{}, history workorder.StatusHistory) (int64, error) {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+
+	res, err := updateStatus(tx, m, data, history)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	return res, nil
+}
+
+// updateStatus locks the work order, applies data and records the transition from the locked status.
+// When history.FromStatus is set, the change is only applied while the work order still has that status.
+func updateStatus(tx *gorm.DB, m workorder.WorkOrder, data map[string]interface{}, history workorder.StatusHistory) (int64, error) {
+	var current workorder.WorkOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").Where("id = ?", m.Id).First(&current).Error; err != nil {
+		return 0, err
+	}
+	if history.FromStatus != nil && *history.FromStatus != current.Status {
+		return 0, workorder.ErrStatusChanged
+	}
+
+	res := tx.Model(&current).Updates(data)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+
+	history.FromStatus = &current.Status
+	if err := tx.Create(&history).Error; err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected, nil
+}
+
+func (r *repo) Cancel(m workorder.WorkOrder, data map[string]interface{}, history workorder.StatusHistory) (int64, error) {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+
+	rows, err := updateStatus(tx, m, data, history)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// give back every part still attached to the work order
+	var parts []workorder.PartWorkOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("work_order_id = ?", m.Id).Find(&parts).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	for _, part := range parts {
+		if _, err := adjustStock(tx, part.SparepartId, part.Quantity); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if len(parts) > 0 {
+		err := tx.Model(&workorder.PartWorkOrder{}).Where("work_order_id = ?", m.Id).Updates(map[string]interface{}{
+			"deleted_at": time.Now(),
+			"deleted_by": m.UpdatedBy,
+		}).Error
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	return rows, nil
+}
+
+// Assign hands the work order to assignment.MechanicId unless the mechanic already has more than
+// maxActive active work orders. Assignments of the same mechanic are serialised with an advisory lock.
+func (r *repo) Assign(m workorder.WorkOrder, data map[string]interface{}, assignment workorder.Assignment, maxActive int) (int64, error) {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+
+	var current workorder.WorkOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").Where("id = ?", m.Id).First(&current).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if workorder.IsFinal(current.Status) {
+		tx.Rollback()
+		return 0, workorder.ErrNotAssignable
+	}
+
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "mechanic:"+assignment.MechanicId).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	active, err := countActiveByMechanic(tx, assignment.MechanicId)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if active > int64(maxActive) {
+		tx.Rollback()
+		return 0, workorder.ErrMechanicBusy
+	}
+
+	res := tx.Model(&m).Where("id = ?", m.Id).Updates(data)
+	if res.Error != nil {
+		tx.Rollback()
+		return 0, res.Error
+	}
+
+	if err := tx.Create(&assignment).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected, nil
+}
+
+func (r *repo) GetAssignments(workOrderId string) ([]workorder.Assignment, error) {
+	var ret []workorder.Assignment
+	if err := r.DB.Where("work_order_id = ?", workOrderId).Order("assigned_at asc").Find(&ret).Error; err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *repo) GetStatusHistory(workOrderId string) ([]workorder.StatusHistory, error) {
+	var ret []workorder.StatusHistory
+	if err := r.DB.Where("work_order_id = ?", workOrderId).Order("changed_at asc").Find(&ret).Error; err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (r *repo) CountActiveByMechanic(mechanicId string) (int64, error) {
+	return countActiveByMechanic(r.DB, mechanicId)
+}
+
+func countActiveByMechanic(db *gorm.DB, mechanicId string) (int64, error) {
+	var total int64
+	err := db.Model(&workorder.WorkOrder{}).
+		Where("mechanic_id = ? AND status IN ?", mechanicId, []string{utils.StsOnProgress, utils.StsWaitingParts, utils.StsQualityCheck}).
+		Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *repo) GetServiceById(workOrderId, svcId string) (workorder.SvcWorkOrder, error) {
+	var svc workorder.SvcWorkOrder
+	err := r.DB.Select("id, work_order_id, service_id, service_name, price, quantity, status").
+		Where("id = ? AND work_order_id = ?", svcId, workOrderId).First(&svc).Error
+	if err != nil {
+		return workorder.SvcWorkOrder{}, err
+	}
+
+	return svc, nil
+}
+
+func (r *repo) UpdateService(m workorder.SvcWorkOrder, data map[string]interface{}) (int64, error) {
+	res := r.DB.Model(&workorder.SvcWorkOrder{}).Where("id = ? AND work_order_id = ?", m.Id, m.WorkOrderId).Updates(data)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
+
+func (r *repo) GetPartById(workOrderId, partId string) (workorder.PartWorkOrder, error) {
+	var part workorder.PartWorkOrder
+	if err := r.DB.Where("id = ? AND work_order_id = ?", partId, workOrderId).First(&part).Error; err != nil {
+		return workorder.PartWorkOrder{}, err
+	}
+
+	return part, nil
+}
+
+func (r *repo) AddPart(part workorder.PartWorkOrder) (workorder.PartWorkOrder, error) {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return workorder.PartWorkOrder{}, tx.Error
+	}
+
+	if err := lockPartsEditable(tx, part.WorkOrderId); err != nil {
+		tx.Rollback()
+		return workorder.PartWorkOrder{}, err
+	}
+
+	sp, err := adjustStock(tx, part.SparepartId, -part.Quantity)
+	if err != nil {
+		tx.Rollback()
+		return workorder.PartWorkOrder{}, err
+	}
+	part.Price = sp.Price
+
+	if err = tx.Omit("Sparepart").Create(&part).Error; err != nil {
+		tx.Rollback()
+		return workorder.PartWorkOrder{}, err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return workorder.PartWorkOrder{}, err
+	}
+	part.Sparepart = sp
+
+	return part, nil
+}
+
+func (r *repo) UpdatePartQuantity(part workorder.PartWorkOrder) (workorder.PartWorkOrder, error) {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return workorder.PartWorkOrder{}, tx.Error
+	}
+
+	if err := lockPartsEditable(tx, part.WorkOrderId); err != nil {
+		tx.Rollback()
+		return workorder.PartWorkOrder{}, err
+	}
+
+	var current workorder.PartWorkOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND work_order_id = ?", part.Id, part.WorkOrderId).First(&current).Error; err != nil {
+		tx.Rollback()
+		return workorder.PartWorkOrder{}, err
+	}
+
+	sp, err := adjustStock(tx, current.SparepartId, current.Quantity-part.Quantity)
+	if err != nil {
+		tx.Rollback()
+		return workorder.PartWorkOrder{}, err
+	}
+
+	err = tx.Model(&current).Updates(map[string]interface{}{
+		"quantity":   part.Quantity,
+		"updated_at": part.UpdatedAt,
+		"updated_by": part.UpdatedBy,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return workorder.PartWorkOrder{}, err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return workorder.PartWorkOrder{}, err
+	}
+	current.Sparepart = sp
+
+	return current, nil
+}
+
+func (r *repo) RemovePart(part workorder.PartWorkOrder) error {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := lockPartsEditable(tx, part.WorkOrderId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var current workorder.PartWorkOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND work_order_id = ?", part.Id, part.WorkOrderId).First(&current).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := adjustStock(tx, current.SparepartId, current.Quantity); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err := tx.Model(&current).Updates(map[string]interface{}{
+		"deleted_at": time.Now(),
+		"deleted_by": part.DeletedBy,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// lockPartsEditable locks the work order row for the rest of the transaction and rejects part changes on
+// a closed work order, so that a concurrent cancel cannot miss parts added after it restored the stock.
+func lockPartsEditable(tx *gorm.DB, workOrderId string) error {
+	var wo workorder.WorkOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").Where("id = ?", workOrderId).First(&wo).Error; err != nil {
+		return err
+	}
+	if workorder.IsFinal(wo.Status) {
+		return workorder.ErrNotEditable
+	}
+
+	return nil
+}
+
+// adjustStock locks the sparepart row and moves qty units in (positive) or out (negative) of stock.
+// Returning stock is allowed even when the sparepart has been deleted from the catalog in the meantime.
+func adjustStock(tx *gorm.DB, sparepartId string, qty int) (sparepart.Sparepart, error) {
+	if qty >= 0 {
+		tx = tx.Unscoped().Session(&gorm.Session{})
+	}
+
+	var sp sparepart.Sparepart
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", sparepartId).First(&sp).Error; err != nil {
+		return sparepart.Sparepart{}, err
+	}
+
+	if qty == 0 {
+		return sp, nil
+	}
+	if sp.Stock+qty < 0 {
+		return sparepart.Sparepart{}, workorder.ErrInsufficientStock
+	}
+
+	if err := tx.Model(&sp).Update("stock", gorm.Expr("stock + ?", qty)).Error; err != nil {
+		return sparepart.Sparepart{}, err
+	}
+	sp.Stock += qty
+
+	return sp, nil
+}
@@ -2,35 +2,60 @@ package router
 
 import (
 	"net/http"
+	"strings"
+	"time"
+	"workshop-management/internal/domain/auth"
+	"workshop-management/internal/domain/permission"
+	auditHandler "workshop-management/internal/handlers/http/audit"
 	bookingHandler "workshop-management/internal/handlers/http/booking"
+	invoiceHandler "workshop-management/internal/handlers/http/invoice"
+	paymentHandler "workshop-management/internal/handlers/http/payment"
+	permissionHandler "workshop-management/internal/handlers/http/permission"
 	serviceHandler "workshop-management/internal/handlers/http/service"
+	sparepartHandler "workshop-management/internal/handlers/http/sparepart"
 	userHandler "workshop-management/internal/handlers/http/user"
 	vehicleHandler "workshop-management/internal/handlers/http/vehicle"
 	workorderHandler "workshop-management/internal/handlers/http/workorder"
+	auditRepo "workshop-management/internal/repositories/audit"
 	authRepo "workshop-management/internal/repositories/auth"
 	bookingRepo "workshop-management/internal/repositories/booking"
+	invoiceRepo "workshop-management/internal/repositories/invoice"
+	paymentRepo "workshop-management/internal/repositories/payment"
+	permissionRepo "workshop-management/internal/repositories/permission"
 	serviceRepo "workshop-management/internal/repositories/service"
+	sparepartRepo "workshop-management/internal/repositories/sparepart"
 	userRepo "workshop-management/internal/repositories/user"
 	vehicleRepo "workshop-management/internal/repositories/vehicle"
 	workorderRepo "workshop-management/internal/repositories/workorder"
+	auditSvc "workshop-management/internal/services/audit"
 	bookingSvc "workshop-management/internal/services/booking"
+	invoiceSvc "workshop-management/internal/services/invoice"
+	paymentSvc "workshop-management/internal/services/payment"
+	permissionSvc "workshop-management/internal/services/permission"
 	serviceSvc "workshop-management/internal/services/service"
+	sparepartSvc "workshop-management/internal/services/sparepart"
 	userSvc "workshop-management/internal/services/user"
 	vehicleSvc "workshop-management/internal/services/vehicle"
 	workorderSvc "workshop-management/internal/services/workorder"
 	"workshop-management/middlewares"
 	"workshop-management/pkg/logger"
+	"workshop-management/pkg/mailer"
+	"workshop-management/pkg/sms"
 	"workshop-management/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"gorm.io/gorm"
 )
 
 type Routes struct {
-	App *gin.Engine
-	DB  *gorm.DB
+	App   *gin.Engine
+	DB    *gorm.DB
+	Redis *redis.Client
+
+	permissions permission.RepoPermission
 }
 
 func NewRoutes() *Routes {
@@ -49,50 +74,119 @@ func NewRoutes() *Routes {
 	})
 	app.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// public keys for services verifying our access tokens
+	app.GET("/.well-known/jwks.json", func(ctx *gin.Context) {
+		keys, err := utils.JwtKeys()
+		if err != nil {
+			logger.WriteLog(logger.LogLevelError, "JwtKeys; Error: "+err.Error())
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		ctx.Header("Cache-Control", "public, max-age=300")
+		ctx.JSON(http.StatusOK, keys.JWKS())
+	})
+
 	return &Routes{
 		App: app,
 	}
 }
 
+// useRedis reports whether the store selected by the env key is Redis and a client is available.
+func (r *Routes) useRedis(key string) bool {
+	return r.Redis != nil && strings.ToLower(utils.GetEnv(key, "").(string)) == "redis"
+}
+
+// BlacklistRepo returns the token blacklist, kept in Redis when AUTH_BLACKLIST_STORE=redis and in the database otherwise.
+// The admin CLI uses it too, so revocations it makes are seen by the API servers.
+func (r *Routes) BlacklistRepo() auth.RepoAuth {
+	if r.useRedis("AUTH_BLACKLIST_STORE") {
+		return authRepo.NewRedisBlacklistRepo(r.Redis)
+	}
+	return authRepo.NewBlacklistRepo(r.DB)
+}
+
+// loginAttemptRepo returns the failed-login counters, kept in Redis when LOGIN_ATTEMPT_STORE=redis and in
+// memory otherwise.
+func (r *Routes) loginAttemptRepo() auth.RepoLoginAttempt {
+	if r.useRedis("LOGIN_ATTEMPT_STORE") {
+		return authRepo.NewRedisLoginAttemptRepo(r.Redis)
+	}
+	return authRepo.NewMemoryLoginAttemptRepo()
+}
+
+// permissionRepo returns the role→permission mapping. A single cached instance is shared by every route
+// group, so edits made through the permission API take effect immediately on this server.
+func (r *Routes) permissionRepo() permission.RepoPermission {
+	if r.permissions == nil {
+		ttl := time.Duration(utils.GetEnv("PERMISSION_CACHE_TTL", 60).(int)) * time.Second
+		r.permissions = permissionRepo.NewCachedPermissionRepo(permissionRepo.NewPermissionRepo(r.DB), ttl)
+	}
+	return r.permissions
+}
+
+func (r *Routes) middleware() *middlewares.Middleware {
+	return middlewares.NewMiddleware(r.BlacklistRepo(), r.permissionRepo())
+}
+
 func (r *Routes) UserRoutes() {
-	blacklistRepo := authRepo.NewBlacklistRepo(r.DB)
+	blacklistRepo := r.BlacklistRepo()
 	repo := userRepo.NewUserRepo(r.DB)
-	uc := userSvc.NewUserService(repo, blacklistRepo)
+	uc := userSvc.NewUserService(repo, blacklistRepo, authRepo.NewSessionRepo(r.DB), authRepo.NewPasswordResetRepo(r.DB), r.loginAttemptRepo(), mailer.NewMailer(), authRepo.NewVerificationRepo(r.DB), sms.NewSender(), authRepo.NewMfaRepo(r.DB))
 	h := userHandler.NewUserHandler(uc)
-	mdw := middlewares.NewMiddleware(blacklistRepo)
+	mdw := middlewares.NewMiddleware(blacklistRepo, r.permissionRepo())
 
 	user := r.App.Group("/api/user")
 	{
 		user.POST("/register", h.Register)
 		user.POST("/login", h.Login)
+		user.POST("/login/2fa", h.LoginMfa)
+		user.POST("/refresh", h.Refresh)
 
 		userPriv := user.Group("").Use(mdw.AuthMiddleware())
 		{
 			userPriv.POST("/logout", h.Logout)
+			userPriv.POST("/verification", h.RequestVerification)
+			userPriv.POST("/verification/confirm", h.ConfirmVerification)
+			userPriv.POST("/2fa/setup", h.SetupTotp)
+			userPriv.POST("/2fa/enable", h.EnableTotp)
+			userPriv.POST("/2fa/disable", h.DisableTotp)
+			userPriv.POST("/2fa/recovery-codes", h.RegenerateRecoveryCodes)
+			userPriv.GET("/sessions", h.GetSessions)
+			userPriv.DELETE("/sessions", h.RevokeOtherSessions)
+			userPriv.DELETE("/sessions/:id", h.RevokeSession)
 			userPriv.GET("", h.GetUserByAuth)
-			userPriv.GET("/:id", mdw.RoleMiddleware(utils.RoleAdmin, utils.RoleCashier), h.GetUserById)
+			userPriv.GET("/:id", mdw.RequirePermission(permission.UserRead), h.GetUserById)
+			userPriv.POST("/staff", mdw.RequirePermission(permission.UserManage), h.CreateStaff)
+			userPriv.PUT("/:id/role", mdw.RequirePermission(permission.UserManage), h.ChangeRole)
+			userPriv.PUT("/:id/deactivate", mdw.RequirePermission(permission.UserManage), h.Deactivate)
+			userPriv.PUT("/:id/activate", mdw.RequirePermission(permission.UserManage), h.Activate)
+			userPriv.POST("/:id/force-reset", mdw.RequirePermission(permission.UserManage), h.ForceResetPassword)
+			userPriv.POST("/:id/unlock", mdw.RequirePermission(permission.UserManage), h.Unlock)
+			userPriv.DELETE("/:id/2fa", mdw.RequirePermission(permission.UserManage), h.ResetTotp)
 			userPriv.PUT("", h.Update)
 			userPriv.PUT("/change/password", h.ChangePassword)
 			userPriv.DELETE("", h.Delete)
 		}
 	}
 
-	r.App.GET("/api/users", mdw.AuthMiddleware(), mdw.RoleMiddleware(utils.RoleAdmin, utils.RoleCashier), h.GetAllUsers)
+	r.App.GET("/api/users", mdw.AuthMiddleware(), mdw.RequirePermission(permission.UserRead), h.GetAllUsers)
+	r.App.POST("/api/forgot-password", h.ForgotPassword)
+	r.App.POST("/api/reset-password", h.ResetPassword)
 }
 
 func (r *Routes) VehicleRoutes() {
 	repo := vehicleRepo.NewVehicleRepo(r.DB)
 	uc := vehicleSvc.NewVehicleService(repo)
 	h := vehicleHandler.NewVehicleHandler(uc)
-	mdw := middlewares.NewMiddleware(authRepo.NewBlacklistRepo(r.DB))
+	mdw := r.middleware()
 
 	r.App.GET("/api/vehicles", mdw.AuthMiddleware(), h.Fetch)
 	vehicle := r.App.Group("/api/vehicle").Use(mdw.AuthMiddleware())
 	{
 		vehicle.POST("", h.Create)
 		vehicle.GET("/:id", h.GetById)
-		vehicle.PUT("/:id", mdw.RoleMiddleware(utils.RoleAdmin, utils.RoleCustomer), h.Update)
-		vehicle.DELETE("/:id", mdw.RoleMiddleware(utils.RoleAdmin, utils.RoleCustomer), h.Delete)
+		vehicle.PUT("/:id", mdw.RequirePermission(permission.VehicleWrite), h.Update)
+		vehicle.DELETE("/:id", mdw.RequirePermission(permission.VehicleWrite), h.Delete)
 	}
 
 }
@@ -101,13 +195,13 @@ func (r *Routes) ServiceRoutes() {
 	repo := serviceRepo.NewServiceRepo(r.DB)
 	uc := serviceSvc.NewSrvService(repo)
 	h := serviceHandler.NewServiceHandler(uc)
-	mdw := middlewares.NewMiddleware(authRepo.NewBlacklistRepo(r.DB))
+	mdw := r.middleware()
 
 	r.App.GET("/api/services", h.Fetch)
 	svc := r.App.Group("/api/service")
 	{
 		svc.GET("/:id", h.GetById)
-		svcPriv := svc.Group("").Use(mdw.AuthMiddleware(), mdw.RoleMiddleware(utils.RoleAdmin))
+		svcPriv := svc.Group("").Use(mdw.AuthMiddleware(), mdw.RequirePermission(permission.ServiceWrite))
 		{
 			svcPriv.POST("", h.Create)
 			svcPriv.PUT("/:id", h.Update)
@@ -116,17 +210,38 @@ func (r *Routes) ServiceRoutes() {
 	}
 }
 
+func (r *Routes) SparepartRoutes() {
+	repo := sparepartRepo.NewSparepartRepo(r.DB)
+	uc := sparepartSvc.NewSparepartService(repo)
+	h := sparepartHandler.NewSparepartHandler(uc)
+	mdw := r.middleware()
+
+	r.App.GET("/api/spareparts", mdw.AuthMiddleware(), h.Fetch)
+	part := r.App.Group("/api/sparepart").Use(mdw.AuthMiddleware())
+	{
+		part.GET("/:id", h.GetById)
+		part.POST("", mdw.RequirePermission(permission.SparepartWrite), h.Create)
+		part.PUT("/:id", mdw.RequirePermission(permission.SparepartWrite), h.Update)
+		part.DELETE("/:id", mdw.RequirePermission(permission.SparepartWrite), h.Delete)
+	}
+}
+
 func (r *Routes) BookingRoutes() {
 	repo := bookingRepo.NewBookingRepo(r.DB)
-	uc := bookingSvc.NewServiceBooking(repo)
+	uc := bookingSvc.NewServiceBooking(repo, vehicleRepo.NewVehicleRepo(r.DB), userRepo.NewUserRepo(r.DB))
 	h := bookingHandler.NewBookingHandler(uc)
-	mdw := middlewares.NewMiddleware(authRepo.NewBlacklistRepo(r.DB))
+	mdw := r.middleware()
 
 	r.App.GET("/api/bookings", mdw.AuthMiddleware(), h.Fetch)
 	booking := r.App.Group("/api/booking").Use(mdw.AuthMiddleware())
 	{
 		booking.POST("", h.Create)
+		booking.GET("/slots", h.AvailableSlots)
+		booking.POST("/estimate", h.Estimate)
 		booking.GET("/:id", h.GetBookingById)
+		booking.PUT("/:id", h.Update)
+		booking.GET("/:id/history", h.GetHistory)
+		booking.POST("/:id/quote", h.AcceptQuote)
 		booking.PUT("/:id/status", h.UpdateStatus)
 	}
 }
@@ -134,17 +249,78 @@ func (r *Routes) BookingRoutes() {
 func (r *Routes) WorkOrderRoutes() {
 	repo := workorderRepo.NewWorkOrderRepo(r.DB)
 	bookRepo := bookingRepo.NewBookingRepo(r.DB)
-	uc := workorderSvc.NewServiceWorkOrder(repo, bookRepo)
+	invSvc := invoiceSvc.NewServiceInvoice(invoiceRepo.NewInvoiceRepo(r.DB), repo, bookRepo)
+	uc := workorderSvc.NewServiceWorkOrder(repo, bookRepo, userRepo.NewUserRepo(r.DB), invSvc)
 	h := workorderHandler.NewWorkOrderHandler(uc)
-	mdw := middlewares.NewMiddleware(authRepo.NewBlacklistRepo(r.DB))
+	mdw := r.middleware()
 
 	r.App.GET("/api/workorders", mdw.AuthMiddleware(), h.Fetch)
+	r.App.GET("/api/mechanic/workorders", mdw.AuthMiddleware(), mdw.RequirePermission(permission.WorkOrderQueue), h.FetchAssigned)
 
 	workorder := r.App.Group("/api/workorder").Use(mdw.AuthMiddleware())
 	{
-		workorder.POST("/from-booking/:id", mdw.RoleMiddleware(utils.RoleAdmin, utils.RoleCashier), h.CreateFromBooking)
+		workorder.POST("/from-booking/:id", mdw.RequirePermission(permission.WorkOrderCreate), h.CreateFromBooking)
 		workorder.GET("/:id", h.GetById)
-		workorder.PUT("/:id/assign-mechanic", mdw.RoleMiddleware(utils.RoleAdmin, utils.RoleCashier), h.AssignMechanic)
-		workorder.PUT("/:id/status", mdw.RoleMiddleware(utils.RoleAdmin, utils.RoleCashier), h.UpdateStatus)
+		workorder.PUT("/:id/assign-mechanic", mdw.RequirePermission(permission.WorkOrderAssign), h.AssignMechanic)
+		workorder.GET("/:id/assignments", mdw.RequirePermission(permission.WorkOrderAssign), h.GetAssignments)
+		workorder.GET("/:id/history", h.GetStatusHistory)
+		workorder.PUT("/:id/status", mdw.RequirePermission(permission.WorkOrderUpdate), h.UpdateStatus)
+		workorder.PUT("/:id/notes", mdw.RequirePermission(permission.WorkOrderUpdate), h.UpdateNotes)
+		workorder.PUT("/:id/services/:svcId/status", mdw.RequirePermission(permission.WorkOrderUpdate), h.UpdateServiceStatus)
+		workorder.POST("/:id/parts", mdw.RequirePermission(permission.WorkOrderParts), h.AddPart)
+		workorder.PUT("/:id/parts/:partId", mdw.RequirePermission(permission.WorkOrderParts), h.UpdatePart)
+		workorder.DELETE("/:id/parts/:partId", mdw.RequirePermission(permission.WorkOrderParts), h.RemovePart)
 	}
 }
+
+func (r *Routes) InvoiceRoutes() {
+	repo := invoiceRepo.NewInvoiceRepo(r.DB)
+	woRepo := workorderRepo.NewWorkOrderRepo(r.DB)
+	uc := invoiceSvc.NewServiceInvoice(repo, woRepo, bookingRepo.NewBookingRepo(r.DB))
+	h := invoiceHandler.NewInvoiceHandler(uc)
+	mdw := r.middleware()
+
+	r.App.GET("/api/invoices", mdw.AuthMiddleware(), mdw.RequirePermission(permission.InvoiceRead), h.Fetch)
+
+	invoice := r.App.Group("/api/invoice").Use(mdw.AuthMiddleware())
+	{
+		invoice.POST("/from-workorder/:id", mdw.RequirePermission(permission.InvoiceCreate), h.CreateFromWorkOrder)
+		invoice.GET("/:id", mdw.RequirePermission(permission.InvoiceRead), h.GetById)
+	}
+}
+
+func (r *Routes) PaymentRoutes() {
+	repo := paymentRepo.NewPaymentRepo(r.DB)
+	uc := paymentSvc.NewServicePayment(repo, invoiceRepo.NewInvoiceRepo(r.DB))
+	h := paymentHandler.NewPaymentHandler(uc)
+	mdw := r.middleware()
+
+	invoice := r.App.Group("/api/invoice").Use(mdw.AuthMiddleware())
+	{
+		invoice.POST("/:id/payments", mdw.RequirePermission(permission.PaymentCreate), h.Create)
+		invoice.GET("/:id/payments", mdw.RequirePermission(permission.PaymentRead), h.GetByInvoiceId)
+	}
+
+	r.App.GET("/api/payment/:id", mdw.AuthMiddleware(), mdw.RequirePermission(permission.PaymentRead), h.GetById)
+}
+
+func (r *Routes) PermissionRoutes() {
+	uc := permissionSvc.NewServicePermission(r.permissionRepo())
+	h := permissionHandler.NewPermissionHandler(uc)
+	mdw := r.middleware()
+
+	perm := r.App.Group("/api/permissions").Use(mdw.AuthMiddleware(), mdw.RequirePermission(permission.PermissionManage))
+	{
+		perm.GET("", h.Fetch)
+		perm.GET("/roles", h.FetchRoles)
+		perm.PUT("/roles/:role", h.UpdateRole)
+	}
+}
+
+func (r *Routes) AuditRoutes() {
+	uc := auditSvc.NewServiceAudit(auditRepo.NewAuditRepo(r.DB))
+	h := auditHandler.NewAuditHandler(uc)
+	mdw := r.middleware()
+
+	r.App.GET("/api/audit", mdw.AuthMiddleware(), mdw.RequirePermission(permission.AuditRead), h.Fetch)
+}
@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"time"
+	"workshop-management/internal/domain/audit"
+	"workshop-management/pkg/filter"
+)
+
+type ServiceAudit struct {
+	AuditRepo audit.RepoAudit
+}
+
+func NewServiceAudit(auditRepo audit.RepoAudit) *ServiceAudit {
+	return &ServiceAudit{
+		AuditRepo: auditRepo,
+	}
+}
+
+// Fetch lists audit events. The from and to filters bound created_at and accept a date or an RFC 3339
+// time; a date in `to` includes the whole day.
+func (s *ServiceAudit) Fetch(params filter.BaseParams) ([]audit.Event, int64, error) {
+	for _, key := range []string{"from", "to"} {
+		value, ok := params.Filters[key]
+		if !ok {
+			continue
+		}
+
+		raw, _ := value.(string)
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			params.Filters[key] = t
+		} else if t, err = time.ParseInLocation(time.DateOnly, raw, time.Local); err == nil {
+			if key == "to" {
+				t = t.AddDate(0, 0, 1)
+			}
+			params.Filters[key] = t
+		} else {
+			return nil, 0, audit.ErrInvalidTime
+		}
+	}
+
+	return s.AuditRepo.Fetch(params)
+}
@@ -1,77 +1,362 @@
 package booking
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 	"workshop-management/internal/domain/booking"
+	"workshop-management/internal/domain/service"
+	"workshop-management/internal/domain/user"
+	"workshop-management/internal/domain/vehicle"
 	"workshop-management/internal/dto"
+	"workshop-management/internal/policy"
 	"workshop-management/pkg/filter"
 	"workshop-management/utils"
+
+	"gorm.io/gorm"
 )
 
 type ServiceBooking struct {
 	BookingRepo booking.RepoBooking
+	VehicleRepo vehicle.RepoVehicle
+	UserRepo    user.RepoUser
 }
 
-func NewServiceBooking(bookingRepo booking.RepoBooking) *ServiceBooking {
+func NewServiceBooking(bookingRepo booking.RepoBooking, vehicleRepo vehicle.RepoVehicle, userRepo user.RepoUser) *ServiceBooking {
 	return &ServiceBooking{
 		BookingRepo: bookingRepo,
+		VehicleRepo: vehicleRepo,
+		UserRepo:    userRepo,
 	}
 }
 
-func (s *ServiceBooking) Create(userId string, req dto.CreateBooking) (booking.Booking, error) {
+// WithContext returns a copy of the service bound to the request in ctx, so that booking changes are
+// audited with the caller and log id.
+func (s *ServiceBooking) WithContext(ctx context.Context) *ServiceBooking {
+	c := *s
+	c.BookingRepo = s.BookingRepo.WithContext(ctx)
+	c.VehicleRepo = s.VehicleRepo.WithContext(ctx)
+	c.UserRepo = s.UserRepo.WithContext(ctx)
+	return &c
+}
+
+// checkVerified rejects customers who have not verified their contact yet when BOOKING_REQUIRE_VERIFIED
+// is enabled. Staff are never blocked.
+func (s *ServiceBooking) checkVerified(actor policy.Actor) error {
+	if actor.IsStaff() || !utils.GetEnv("BOOKING_REQUIRE_VERIFIED", false).(bool) {
+		return nil
+	}
+
+	data, err := s.UserRepo.GetByID(actor.UserId)
+	if err != nil {
+		return err
+	}
+	if !data.IsVerified() {
+		return booking.ErrUnverifiedCustomer
+	}
+	return nil
+}
+
+func (s *ServiceBooking) Create(actor policy.Actor, req dto.CreateBooking) (booking.Booking, error) {
+	if err := s.checkVerified(actor); err != nil {
+		return booking.Booking{}, err
+	}
+
+	vehicleData, err := s.bookableVehicle(actor, req.VehicleID)
+	if err != nil {
+		return booking.Booking{}, err
+	}
+
+	serviceIDs, dataService, err := s.validateServices(req.ServiceIDs)
+	if err != nil {
+		return booking.Booking{}, err
+	}
+
+	schedule, err := booking.LoadSchedule()
+	if err != nil {
+		return booking.Booking{}, err
+	}
+
+	slot, err := schedule.SlotAt(req.BookingDate, time.Now())
+	if err != nil {
+		return booking.Booking{}, err
+	}
+
 	bookingID := utils.CreateUUID()
 	bookingData := booking.Booking{
 		Id:          bookingID,
-		UserId:      userId,
-		VehicleId:   req.VehicleID,
-		BookingDate: req.BookingDate,
+		UserId:      vehicleData.UserId, // staff book on behalf of the vehicle owner
+		VehicleId:   vehicleData.Id,
+		BookingDate: req.BookingDate.In(schedule.Location),
 		Notes:       req.Notes,
 		Status:      utils.StsPending,
 		CreatedAt:   time.Now(),
 	}
 
 	var bookingServices []booking.BookService
-
-	for _, serviceID := range req.ServiceIDs {
+	for _, serviceID := range serviceIDs {
 		bookingServices = append(bookingServices, booking.BookService{
 			Id:        utils.CreateUUID(),
 			BookingID: bookingID,
 			ServiceID: serviceID,
 		})
 	}
-	dataService, err := s.BookingRepo.GetServicesByIDs(req.ServiceIDs)
+	bookingData.Services = dataService
+
+	history := booking.StatusHistory{
+		Id:        utils.CreateUUID(),
+		BookingId: bookingID,
+		ToStatus:  utils.StsPending,
+		ChangedAt: bookingData.CreatedAt,
+		ChangedBy: actor.UserId,
+	}
+
+	if err := s.BookingRepo.Create(bookingData, bookingServices, slot, history); err != nil {
+		return booking.Booking{}, err
+	}
+
+	return bookingData, nil
+}
+
+// Update lets the customer who made a pending booking reschedule it, change its notes or replace its
+// services. The date is validated like a new booking, without counting the booking against its own slot.
+func (s *ServiceBooking) Update(actor policy.Actor, id string, req dto.UpdateBooking) (booking.Booking, error) {
+	bookingData, err := s.GetByID(actor, id)
 	if err != nil {
 		return booking.Booking{}, err
 	}
-	bookingData.Services = dataService
+	if bookingData.UserId != actor.UserId {
+		return booking.Booking{}, booking.ErrNotOwner
+	}
+	if bookingData.Status != utils.StsPending {
+		return booking.Booking{}, booking.ErrNotEditable
+	}
+
+	data := map[string]interface{}{
+		"updated_at": time.Now(),
+		"updated_by": actor.UserId,
+	}
+
+	// the slot is only checked when the booking moves, so notes and services of a booking whose
+	// slot has since closed or filled up can still be edited
+	var slot *booking.Slot
+	if !req.BookingDate.IsZero() && !req.BookingDate.Equal(bookingData.BookingDate) {
+		schedule, err := booking.LoadSchedule()
+		if err != nil {
+			return booking.Booking{}, err
+		}
+
+		bookingDate := req.BookingDate.In(schedule.Location)
+		at, err := schedule.SlotAt(bookingDate, time.Now())
+		if err != nil {
+			return booking.Booking{}, err
+		}
+		slot = &at
+		data["booking_date"] = bookingDate
+	}
+
+	// notes is a pointer so that they can be cleared with an empty string
+	if req.Notes != nil {
+		data["notes"] = *req.Notes
+	}
+
+	var serviceIDs []string
+	if len(req.ServiceIDs) > 0 {
+		if serviceIDs, _, err = s.validateServices(req.ServiceIDs); err != nil {
+			return booking.Booking{}, err
+		}
+	}
 
-	if err := s.BookingRepo.Create(bookingData, bookingServices); err != nil {
+	if err = s.BookingRepo.UpdateDetails(booking.Booking{Id: id}, data, serviceIDs, slot); err != nil {
 		return booking.Booking{}, err
 	}
 
-	return bookingData, nil
+	return s.BookingRepo.GetById(id)
+}
+
+// bookableVehicle loads a vehicle the actor may book. Other customers' vehicles are reported exactly
+// like missing ones.
+func (s *ServiceBooking) bookableVehicle(actor policy.Actor, vehicleId string) (vehicle.Vehicle, error) {
+	vehicleData, err := s.VehicleRepo.GetById(vehicleId)
+	if err == nil {
+		err = actor.Authorize(vehicleData.UserId)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return vehicle.Vehicle{}, booking.ErrVehicleNotFound
+	}
+	if err != nil {
+		return vehicle.Vehicle{}, err
+	}
+
+	return vehicleData, nil
+}
+
+// validateServices collapses duplicate ids and loads the services in request order. Unknown or deleted
+// services are reported together in an InvalidServicesError.
+func (s *ServiceBooking) validateServices(ids []string) ([]string, []service.Service, error) {
+	normalized := make([]string, len(ids))
+	for i, id := range ids {
+		normalized[i] = strings.ToLower(strings.TrimSpace(id))
+	}
+	ids = utils.Unique(normalized)
+
+	found, err := s.BookingRepo.GetServicesByIDs(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byId := make(map[string]service.Service, len(found))
+	for _, svc := range found {
+		byId[svc.Id] = svc
+	}
+
+	services := make([]service.Service, 0, len(ids))
+	var invalid []booking.ServiceError
+	for _, id := range ids {
+		svc, ok := byId[id]
+		if !ok {
+			invalid = append(invalid, booking.ServiceError{ServiceId: id, Message: "service not found"})
+			continue
+		}
+		services = append(services, svc)
+	}
+	if len(invalid) > 0 {
+		return nil, nil, &booking.InvalidServicesError{Services: invalid}
+	}
+
+	return ids, services, nil
 }
 
-func (s *ServiceBooking) GetByID(id string) (booking.Booking, error) {
+// Estimate prices the selected services for a vehicle with the current catalog and pricing rules,
+// without storing anything.
+func (s *ServiceBooking) Estimate(actor policy.Actor, req dto.EstimateBooking) (booking.Quote, error) {
+	vehicleData, err := s.bookableVehicle(actor, req.VehicleID)
+	if err != nil {
+		return booking.Quote{}, err
+	}
+
+	_, services, err := s.validateServices(req.ServiceIDs)
+	if err != nil {
+		return booking.Quote{}, err
+	}
+
+	rules, err := booking.LoadPriceRules()
+	if err != nil {
+		return booking.Quote{}, err
+	}
+
+	quote := rules.Quote(services)
+	quote.VehicleId = vehicleData.Id
+
+	return quote, nil
+}
+
+// AcceptQuote prices a pending booking and stores the quote with it, so that later catalog changes do
+// not affect what the customer pays. Accepting again replaces the previous quote.
+func (s *ServiceBooking) AcceptQuote(actor policy.Actor, id string) (booking.Quote, error) {
+	bookingData, err := s.GetByID(actor, id)
+	if err != nil {
+		return booking.Quote{}, err
+	}
+	if bookingData.UserId != actor.UserId {
+		return booking.Quote{}, booking.ErrNotOwner
+	}
+	if bookingData.Status != utils.StsPending {
+		return booking.Quote{}, booking.ErrNotEditable
+	}
+
+	rules, err := booking.LoadPriceRules()
+	if err != nil {
+		return booking.Quote{}, err
+	}
+
+	now := time.Now()
+	quote := rules.Quote(bookingData.Services)
+	quote.Id = utils.CreateUUID()
+	quote.BookingId = id
+	quote.VehicleId = bookingData.VehicleId
+	quote.AcceptedAt = &now
+	quote.AcceptedBy = actor.UserId
+	for i := range quote.Items {
+		quote.Items[i].Id = utils.CreateUUID()
+		quote.Items[i].QuoteId = quote.Id
+	}
+
+	if err = s.BookingRepo.SaveQuote(quote); err != nil {
+		return booking.Quote{}, err
+	}
+
+	return quote, nil
+}
+
+// AvailableSlots lists the slots of the given day that have not started yet and still have free capacity.
+func (s *ServiceBooking) AvailableSlots(date string) ([]booking.Slot, error) {
+	schedule, err := booking.LoadSchedule()
+	if err != nil {
+		return nil, err
+	}
+
+	day, err := time.ParseInLocation(time.DateOnly, date, schedule.Location)
+	if err != nil {
+		return nil, booking.ErrInvalidDate
+	}
+
+	now := time.Now()
+	slots := make([]booking.Slot, 0)
+	for _, slot := range schedule.Slots(day) {
+		if !slot.Start.After(now) {
+			continue
+		}
+
+		booked, err := s.BookingRepo.CountActiveBetween(slot.Start, slot.End)
+		if err != nil {
+			return nil, err
+		}
+
+		slot.Booked = int(booked)
+		slot.Available = slot.Capacity - slot.Booked
+		if slot.Available > 0 {
+			slots = append(slots, slot)
+		}
+	}
+
+	return slots, nil
+}
+
+// GetByID returns the booking when the actor owns it (or is staff); foreign bookings are reported as not found.
+func (s *ServiceBooking) GetByID(actor policy.Actor, id string) (booking.Booking, error) {
 	bookingData, err := s.BookingRepo.GetById(id)
 	if err != nil {
 		return booking.Booking{}, err
 	}
 
+	if err = actor.Authorize(bookingData.UserId); err != nil {
+		return booking.Booking{}, err
+	}
+
 	return bookingData, nil
 }
 
-func (s *ServiceBooking) Fetch(params filter.BaseParams) ([]booking.Booking, int64, error) {
+// Fetch lists bookings, restricted to the actor's own bookings unless the actor is staff.
+func (s *ServiceBooking) Fetch(actor policy.Actor, params filter.BaseParams) ([]booking.Booking, int64, error) {
+	if ownerId := actor.OwnerScope(); ownerId != "" {
+		if params.Filters == nil {
+			params.Filters = map[string]interface{}{}
+		}
+		params.Filters["user_id"] = ownerId
+	}
+
 	return s.BookingRepo.Fetch(params)
 }
 
-func (s *ServiceBooking) UpdateStatus(id, userId, role string, req dto.UpdateBookingStatus) (int64, error) {
-	bookingData, err := s.BookingRepo.GetById(id)
+func (s *ServiceBooking) UpdateStatus(actor policy.Actor, id string, req dto.UpdateBookingStatus) (int64, error) {
+	bookingData, err := s.GetByID(actor, id)
 	if err != nil {
 		return 0, err
 	}
+	userId, role := actor.UserId, actor.Role
 
 	newStatus := strings.ToLower(req.Status)
 	var data map[string]interface{}
@@ -112,5 +397,25 @@ func (s *ServiceBooking) UpdateStatus(id, userId, role string, req dto.UpdateBoo
 		return 0, fmt.Errorf("role %s is not allowed to update booking with status %s", role, bookingData.Status)
 	}
 
-	return s.BookingRepo.Update(booking.Booking{Id: id}, data)
+	// the repository only applies the change while the booking still has the validated status
+	history := booking.StatusHistory{
+		Id:         utils.CreateUUID(),
+		BookingId:  id,
+		FromStatus: &bookingData.Status,
+		ToStatus:   newStatus,
+		Reason:     req.Reason,
+		ChangedAt:  time.Now(),
+		ChangedBy:  userId,
+	}
+
+	return s.BookingRepo.UpdateStatus(booking.Booking{Id: id}, data, history)
+}
+
+// GetHistory returns the status transitions of a booking the actor may see, oldest first.
+func (s *ServiceBooking) GetHistory(actor policy.Actor, id string) ([]booking.StatusHistory, error) {
+	if _, err := s.GetByID(actor, id); err != nil {
+		return nil, err
+	}
+
+	return s.BookingRepo.GetStatusHistory(id)
 }
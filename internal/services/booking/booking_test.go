@@ -0,0 +1,256 @@
+package booking
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"workshop-management/internal/domain/booking"
+	"workshop-management/internal/domain/service"
+	"workshop-management/internal/domain/vehicle"
+	"workshop-management/internal/dto"
+	"workshop-management/internal/policy"
+	"workshop-management/pkg/filter"
+	"workshop-management/utils"
+
+	"gorm.io/gorm"
+)
+
+// fakeBookingRepo keeps bookings in memory and applies the user_id filter like the SQL repository.
+type fakeBookingRepo struct {
+	booking.RepoBooking
+	rows    []booking.Booking
+	created []booking.Booking
+	slots   []*booking.Slot
+}
+
+func (r *fakeBookingRepo) GetById(id string) (booking.Booking, error) {
+	for _, b := range r.rows {
+		if b.Id == id {
+			return b, nil
+		}
+	}
+	return booking.Booking{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeBookingRepo) Fetch(params filter.BaseParams) ([]booking.Booking, int64, error) {
+	var ret []booking.Booking
+	for _, b := range r.rows {
+		if userId, ok := params.Filters["user_id"]; ok && userId != b.UserId {
+			continue
+		}
+		ret = append(ret, b)
+	}
+	return ret, int64(len(ret)), nil
+}
+
+func (r *fakeBookingRepo) GetStatusHistory(bookingId string) ([]booking.StatusHistory, error) {
+	return []booking.StatusHistory{{BookingId: bookingId, ToStatus: utils.StsPending}}, nil
+}
+
+func (r *fakeBookingRepo) UpdateStatus(m booking.Booking, data map[string]interface{}, history booking.StatusHistory) (int64, error) {
+	return 1, nil
+}
+
+func (r *fakeBookingRepo) GetServicesByIDs(ids []string) ([]service.Service, error) {
+	ret := make([]service.Service, 0, len(ids))
+	for _, id := range ids {
+		ret = append(ret, service.Service{Id: id})
+	}
+	return ret, nil
+}
+
+func (r *fakeBookingRepo) Create(m booking.Booking, bookingServices []booking.BookService, slot booking.Slot, history booking.StatusHistory) error {
+	r.created = append(r.created, m)
+	return nil
+}
+
+func (r *fakeBookingRepo) UpdateDetails(m booking.Booking, data map[string]interface{}, serviceIDs []string, slot *booking.Slot) error {
+	r.slots = append(r.slots, slot)
+	return nil
+}
+
+type fakeVehicleRepo struct {
+	vehicle.RepoVehicle
+	rows []vehicle.Vehicle
+}
+
+func (r *fakeVehicleRepo) GetById(id string) (vehicle.Vehicle, error) {
+	for _, v := range r.rows {
+		if v.Id == id {
+			return v, nil
+		}
+	}
+	return vehicle.Vehicle{}, gorm.ErrRecordNotFound
+}
+
+var (
+	customer = policy.Actor{UserId: "u1", Role: utils.RoleCustomer}
+	cashier  = policy.Actor{UserId: "c1", Role: utils.RoleCashier}
+)
+
+func newService() (*ServiceBooking, *fakeBookingRepo) {
+	bookings := &fakeBookingRepo{rows: []booking.Booking{
+		{Id: "b1", UserId: "u1", Status: utils.StsPending},
+		{Id: "b2", UserId: "u2", Status: utils.StsPending},
+		{Id: "b3", UserId: "u1", Status: utils.StsPending},
+	}}
+	vehicles := &fakeVehicleRepo{rows: []vehicle.Vehicle{
+		{Id: "v1", UserId: "u1"},
+		{Id: "v2", UserId: "u2"},
+	}}
+	return NewServiceBooking(bookings, vehicles, nil), bookings
+}
+
+func TestFetchScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   policy.Actor
+		filters map[string]interface{}
+		want    []string
+	}{
+		{"customer sees only own bookings", customer, map[string]interface{}{}, []string{"b1", "b3"}},
+		{"customer cannot widen the scope with a filter", customer, map[string]interface{}{"user_id": "u2"}, []string{"b1", "b3"}},
+		{"staff see every booking", cashier, map[string]interface{}{}, []string{"b1", "b2", "b3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newService()
+
+			rows, _, err := s.Fetch(tt.actor, filter.BaseParams{Filters: tt.filters})
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("Fetch() returned %d rows, want %d", len(rows), len(tt.want))
+			}
+			for i, b := range rows {
+				if b.Id != tt.want[i] {
+					t.Fatalf("Fetch()[%d] = %s, want %s", i, b.Id, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOwnership(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   policy.Actor
+		id      string
+		wantErr error
+	}{
+		{"customer on own booking", customer, "b1", nil},
+		{"customer on foreign booking", customer, "b2", gorm.ErrRecordNotFound},
+		{"customer on missing booking", customer, "b9", gorm.ErrRecordNotFound},
+		{"staff on any booking", cashier, "b2", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newService()
+
+			if _, err := s.GetByID(tt.actor, tt.id); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetByID() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := s.GetHistory(tt.actor, tt.id); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetHistory() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := s.UpdateStatus(tt.actor, tt.id, dto.UpdateBookingStatus{Status: utils.StsCancelled}); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateStatus() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateVehicleOwnership(t *testing.T) {
+	schedule, err := booking.LoadSchedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tomorrow := time.Now().In(schedule.Location).AddDate(0, 0, 1)
+	bookingDate := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, schedule.Location).Add(schedule.Open)
+
+	tests := []struct {
+		name      string
+		actor     policy.Actor
+		vehicleId string
+		wantErr   error
+		wantOwner string
+	}{
+		{"customer books own vehicle", customer, "v1", nil, "u1"},
+		{"customer books foreign vehicle", customer, "v2", booking.ErrVehicleNotFound, ""},
+		{"customer books missing vehicle", customer, "v9", booking.ErrVehicleNotFound, ""},
+		{"staff book on behalf of the owner", cashier, "v2", nil, "u2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newService()
+
+			data, err := s.Create(tt.actor, dto.CreateBooking{
+				VehicleID:   tt.vehicleId,
+				BookingDate: bookingDate,
+				ServiceIDs:  []string{"s1"},
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(repo.created) != 0 {
+					t.Fatalf("Create() stored a booking on error")
+				}
+				return
+			}
+			if data.UserId != tt.wantOwner || repo.created[0].UserId != tt.wantOwner {
+				t.Fatalf("Create() owner = %s, want %s", data.UserId, tt.wantOwner)
+			}
+		})
+	}
+}
+
+func TestUpdateSlotCheck(t *testing.T) {
+	schedule, err := booking.LoadSchedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(offset int) time.Time {
+		d := time.Now().In(schedule.Location).AddDate(0, 0, offset)
+		return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, schedule.Location).Add(schedule.Open)
+	}
+	notes := "bring the spare key"
+
+	tests := []struct {
+		name      string
+		req       dto.UpdateBooking
+		wantErr   error
+		wantCheck bool
+	}{
+		{"notes only", dto.UpdateBooking{Notes: &notes}, nil, false},
+		{"services only", dto.UpdateBooking{ServiceIDs: []string{"s1"}}, nil, false},
+		{"unchanged date", dto.UpdateBooking{BookingDate: day(-1), Notes: &notes}, nil, false},
+		{"moved to an open slot", dto.UpdateBooking{BookingDate: day(1)}, nil, true},
+		{"moved to the past", dto.UpdateBooking{BookingDate: day(-2)}, booking.ErrPastDate, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newService()
+			repo.rows[0].BookingDate = day(-1) // the slot of the booking itself has already passed
+
+			_, err := s.Update(customer, "b1", tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(repo.slots) != 0 {
+					t.Fatalf("Update() stored changes on error")
+				}
+				return
+			}
+			if checked := repo.slots[0] != nil; checked != tt.wantCheck {
+				t.Fatalf("Update() slot checked = %v, want %v", checked, tt.wantCheck)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package invoice
+
+import (
+	"errors"
+	"math"
+	"time"
+	"workshop-management/internal/domain/booking"
+	"workshop-management/internal/domain/invoice"
+	"workshop-management/internal/domain/workorder"
+	"workshop-management/pkg/filter"
+	"workshop-management/utils"
+
+	"gorm.io/gorm"
+)
+
+type ServiceInvoice struct {
+	InvoiceRepo   invoice.RepoInvoice
+	WorkOrderRepo workorder.RepoWorkOrder
+	BookingRepo   booking.RepoBooking
+}
+
+func NewServiceInvoice(invoiceRepo invoice.RepoInvoice, workOrderRepo workorder.RepoWorkOrder, bookingRepo booking.RepoBooking) *ServiceInvoice {
+	return &ServiceInvoice{
+		InvoiceRepo:   invoiceRepo,
+		WorkOrderRepo: workOrderRepo,
+		BookingRepo:   bookingRepo,
+	}
+}
+
+func (s *ServiceInvoice) CreateFromWorkOrder(workOrderId, userId string) (invoice.Invoice, error) {
+	wo, err := s.WorkOrderRepo.GetById(workOrderId)
+	if err != nil {
+		return invoice.Invoice{}, err
+	}
+
+	if wo.Status != utils.StsCompleted {
+		return invoice.Invoice{}, invoice.ErrNotCompleted
+	}
+
+	if _, err = s.InvoiceRepo.GetByWorkOrderId(workOrderId); err == nil {
+		return invoice.Invoice{}, invoice.ErrAlreadyInvoiced
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return invoice.Invoice{}, err
+	}
+
+	now := time.Now()
+	invoiceId := utils.CreateUUID()
+
+	var (
+		items []invoice.Item
+		total float64
+	)
+	addItem := func(itemType, referenceId, description string, quantity int, unitPrice float64) {
+		// older work order lines were stored with quantity 0, bill them as a single unit
+		if quantity < 1 {
+			quantity = 1
+		}
+		subtotal := roundPrice(unitPrice * float64(quantity))
+		total += subtotal
+
+		items = append(items, invoice.Item{
+			Id:          utils.CreateUUID(),
+			InvoiceId:   invoiceId,
+			ItemType:    itemType,
+			ReferenceId: referenceId,
+			Description: description,
+			Quantity:    quantity,
+			UnitPrice:   unitPrice,
+			Subtotal:    subtotal,
+			CreatedAt:   now,
+		})
+	}
+
+	// skipped service lines were never carried out, so they are neither billed nor counted for the discount
+	var performed []workorder.SvcWorkOrder
+	for _, svc := range wo.Services {
+		if svc.Status == utils.StsSkipped {
+			continue
+		}
+		performed = append(performed, svc)
+		addItem(invoice.ItemTypeService, svc.ServiceId, svc.ServiceName, svc.Quantity, svc.Price)
+	}
+	servicesTotal := total
+	for _, part := range wo.Parts {
+		addItem(invoice.ItemTypePart, part.SparepartId, part.Sparepart.Name, part.Quantity, part.Price)
+	}
+
+	discount, taxRate, err := s.adjustments(wo.BookingId, performed, roundPrice(servicesTotal))
+	if err != nil {
+		return invoice.Invoice{}, err
+	}
+	subtotal := roundPrice(total)
+	tax := roundPrice((subtotal - discount) * taxRate / 100)
+
+	data := invoice.Invoice{
+		Id:          invoiceId,
+		WorkOrderId: workOrderId,
+		Subtotal:    subtotal,
+		Discount:    discount,
+		Tax:         tax,
+		Total:       roundPrice(subtotal - discount + tax),
+		Status:      utils.StsPending,
+		CreatedAt:   now,
+		CreatedBy:   userId,
+		UpdatedAt:   now,
+		UpdatedBy:   userId,
+	}
+
+	if err = s.InvoiceRepo.Create(data, items); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return invoice.Invoice{}, invoice.ErrAlreadyInvoiced
+		}
+		return invoice.Invoice{}, err
+	}
+	data.Items = items
+
+	return data, nil
+}
+
+// adjustments returns the discount and tax rate for the performed services of a work order: those of
+// the accepted quote of its booking, or the current pricing rules when the customer never accepted one.
+// A quoted discount only covers the quoted services that were performed.
+func (s *ServiceInvoice) adjustments(bookingId string, performed []workorder.SvcWorkOrder, servicesTotal float64) (float64, float64, error) {
+	quote, err := s.BookingRepo.GetQuote(bookingId)
+	if err == nil {
+		serviceIds := make(map[string]bool, len(performed))
+		for _, svc := range performed {
+			serviceIds[svc.ServiceId] = true
+		}
+		return math.Min(quote.DiscountFor(serviceIds), servicesTotal), quote.TaxRate, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, 0, err
+	}
+
+	rules, err := booking.LoadPriceRules()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return rules.Discount(servicesTotal, len(performed)), rules.TaxRate, nil
+}
+
+func (s *ServiceInvoice) GetById(id string) (invoice.Invoice, error) {
+	return s.InvoiceRepo.GetById(id)
+}
+
+func (s *ServiceInvoice) Fetch(params filter.BaseParams) ([]invoice.Invoice, int64, error) {
+	return s.InvoiceRepo.Fetch(params)
+}
+
+func roundPrice(v float64) float64 {
+	return math.Round(v*100) / 100
+}
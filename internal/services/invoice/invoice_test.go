@@ -0,0 +1,98 @@
+package invoice
+
+import (
+	"testing"
+	"workshop-management/internal/domain/booking"
+	"workshop-management/internal/domain/invoice"
+	"workshop-management/internal/domain/workorder"
+	"workshop-management/utils"
+
+	"gorm.io/gorm"
+)
+
+type fakeInvoiceRepo struct {
+	invoice.RepoInvoice
+	items []invoice.Item
+}
+
+func (r *fakeInvoiceRepo) GetByWorkOrderId(workOrderId string) (invoice.Invoice, error) {
+	return invoice.Invoice{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeInvoiceRepo) Create(m invoice.Invoice, items []invoice.Item) error {
+	r.items = items
+	return nil
+}
+
+type fakeWorkOrderRepo struct {
+	workorder.RepoWorkOrder
+	wo workorder.WorkOrder
+}
+
+func (r *fakeWorkOrderRepo) GetById(id string) (workorder.WorkOrder, error) {
+	return r.wo, nil
+}
+
+type fakeBookingRepo struct {
+	booking.RepoBooking
+	quote *booking.Quote
+}
+
+func (r *fakeBookingRepo) GetQuote(bookingId string) (booking.Quote, error) {
+	if r.quote == nil {
+		return booking.Quote{}, gorm.ErrRecordNotFound
+	}
+	return *r.quote, nil
+}
+
+func TestCreateFromWorkOrderSkipsSkippedServices(t *testing.T) {
+	t.Setenv("SERVICE_DISCOUNT_RATE", "10")
+	t.Setenv("SERVICE_DISCOUNT_MIN_SERVICES", "2")
+	t.Setenv("TAX_RATE", "0")
+
+	wo := workorder.WorkOrder{
+		Id:        "w1",
+		BookingId: "b1",
+		Status:    utils.StsCompleted,
+		Services: []workorder.SvcWorkOrder{
+			{ServiceId: "s1", ServiceName: "Oil change", Price: 100, Quantity: 1, Status: utils.StsDone},
+			{ServiceId: "s2", ServiceName: "Wheel alignment", Price: 50, Quantity: 1, Status: utils.StsSkipped},
+		},
+	}
+	quote := &booking.Quote{
+		Subtotal: 150,
+		Discount: 15,
+		Items: []booking.QuoteItem{
+			{ServiceId: "s1", Subtotal: 100},
+			{ServiceId: "s2", Subtotal: 50},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		quote        *booking.Quote
+		wantDiscount float64
+	}{
+		// one performed service is below the discount threshold of two
+		{"pricing rules", nil, 0},
+		{"accepted quote", quote, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoices := &fakeInvoiceRepo{}
+			s := NewServiceInvoice(invoices, &fakeWorkOrderRepo{wo: wo}, &fakeBookingRepo{quote: tt.quote})
+
+			data, err := s.CreateFromWorkOrder("w1", "c1")
+			if err != nil {
+				t.Fatalf("CreateFromWorkOrder() error = %v", err)
+			}
+			if len(invoices.items) != 1 || invoices.items[0].ReferenceId != "s1" {
+				t.Fatalf("CreateFromWorkOrder() items = %+v, want only the done service", invoices.items)
+			}
+			if data.Subtotal != 100 || data.Discount != tt.wantDiscount {
+				t.Fatalf("CreateFromWorkOrder() subtotal = %v, discount = %v, want 100, %v", data.Subtotal, data.Discount, tt.wantDiscount)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package payment
+
+import (
+	"strings"
+	"time"
+	"workshop-management/internal/domain/invoice"
+	"workshop-management/internal/domain/payment"
+	"workshop-management/internal/dto"
+	"workshop-management/utils"
+)
+
+type ServicePayment struct {
+	PaymentRepo payment.RepoPayment
+	InvoiceRepo invoice.RepoInvoice
+}
+
+func NewServicePayment(paymentRepo payment.RepoPayment, invoiceRepo invoice.RepoInvoice) *ServicePayment {
+	return &ServicePayment{
+		PaymentRepo: paymentRepo,
+		InvoiceRepo: invoiceRepo,
+	}
+}
+
+func (s *ServicePayment) Create(req dto.AddPayment, invoiceId, userId string) (payment.Payment, invoice.Invoice, error) {
+	method := strings.ToLower(strings.TrimSpace(req.Method))
+	if !payment.ValidMethod(method) {
+		return payment.Payment{}, invoice.Invoice{}, payment.ErrInvalidMethod
+	}
+	if payment.Cents(req.Amount) <= 0 {
+		return payment.Payment{}, invoice.Invoice{}, payment.ErrInvalidAmount
+	}
+
+	now := time.Now()
+	paidAt := now
+	if req.PaidAt != nil {
+		paidAt = *req.PaidAt
+	}
+
+	data := payment.Payment{
+		Id:        utils.CreateUUID(),
+		InvoiceId: invoiceId,
+		Method:    method,
+		Amount:    req.Amount,
+		PaidAt:    paidAt,
+		CreatedAt: now,
+		CreatedBy: userId,
+		UpdatedAt: now,
+		UpdatedBy: userId,
+	}
+
+	inv, err := s.PaymentRepo.Create(data)
+	if err != nil {
+		return payment.Payment{}, invoice.Invoice{}, err
+	}
+
+	return data, inv, nil
+}
+
+func (s *ServicePayment) GetById(id string) (payment.Payment, error) {
+	return s.PaymentRepo.GetById(id)
+}
+
+func (s *ServicePayment) GetByInvoiceId(invoiceId string) ([]payment.Payment, error) {
+	if _, err := s.InvoiceRepo.GetById(invoiceId); err != nil {
+		return nil, err
+	}
+
+	return s.PaymentRepo.GetByInvoiceId(invoiceId)
+}
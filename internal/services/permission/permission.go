@@ -0,0 +1,84 @@
+package permission
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"workshop-management/internal/domain/permission"
+	"workshop-management/internal/dto"
+	"workshop-management/utils"
+)
+
+type ServicePermission struct {
+	PermissionRepo permission.RepoPermission
+}
+
+func NewServicePermission(permissionRepo permission.RepoPermission) *ServicePermission {
+	return &ServicePermission{
+		PermissionRepo: permissionRepo,
+	}
+}
+
+func (s *ServicePermission) GetRegistry() []permission.Definition {
+	return permission.Registry
+}
+
+// GetRoles returns the permissions of every role, admin included with the full registry.
+func (s *ServicePermission) GetRoles() ([]dto.RolePermissions, error) {
+	rows, err := s.PermissionRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	all := make([]string, 0, len(permission.Registry))
+	for _, d := range permission.Registry {
+		all = append(all, d.Name)
+	}
+
+	ret := []dto.RolePermissions{{Role: utils.RoleAdmin, Permissions: all}}
+	for _, role := range permission.EditableRoles {
+		granted := []string{}
+		for _, row := range rows {
+			if row.Role == role {
+				granted = append(granted, row.Permission)
+			}
+		}
+		ret = append(ret, dto.RolePermissions{Role: role, Permissions: granted})
+	}
+
+	return ret, nil
+}
+
+// SetRolePermissions replaces the permissions of a role. Duplicates are ignored and unknown permissions
+// are rejected as a whole, listing every offending name.
+func (s *ServicePermission) SetRolePermissions(userId, role string, req dto.SetRolePermissions) (dto.RolePermissions, error) {
+	if role == utils.RoleAdmin {
+		return dto.RolePermissions{}, permission.ErrImmutableRole
+	}
+	if !slices.Contains(permission.EditableRoles, role) {
+		return dto.RolePermissions{}, permission.ErrInvalidRole
+	}
+
+	var unknown []string
+	granted := []string{}
+	for _, name := range req.Permissions {
+		name = strings.TrimSpace(name)
+		if !permission.IsRegistered(name) {
+			unknown = append(unknown, name)
+			continue
+		}
+		if !slices.Contains(granted, name) {
+			granted = append(granted, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return dto.RolePermissions{}, fmt.Errorf("%w: %s", permission.ErrUnknownPermission, strings.Join(unknown, ", "))
+	}
+	slices.Sort(granted)
+
+	if err := s.PermissionRepo.ReplaceRole(role, granted, userId); err != nil {
+		return dto.RolePermissions{}, err
+	}
+
+	return dto.RolePermissions{Role: role, Permissions: granted}, nil
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"time"
 	"workshop-management/internal/domain/service"
 	"workshop-management/internal/dto"
@@ -18,6 +19,13 @@ func NewSrvService(serviceRepo service.RepoService) *SrvService {
 	}
 }
 
+// WithContext binds the service to the request in ctx for the audit log.
+func (s *SrvService) WithContext(ctx context.Context) *SrvService {
+	c := *s
+	c.ServiceRepo = s.ServiceRepo.WithContext(ctx)
+	return &c
+}
+
 func (s *SrvService) Create(userId string, req dto.AddService) (service.Service, error) {
 	data := service.Service{
 		Id:          utils.CreateUUID(),
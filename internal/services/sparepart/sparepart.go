@@ -0,0 +1,77 @@
+package sparepart
+
+import (
+	"strings"
+	"time"
+	"workshop-management/internal/domain/sparepart"
+	"workshop-management/internal/dto"
+	"workshop-management/pkg/filter"
+	"workshop-management/utils"
+)
+
+type ServiceSparepart struct {
+	SparepartRepo sparepart.RepoSparepart
+}
+
+func NewSparepartService(sparepartRepo sparepart.RepoSparepart) *ServiceSparepart {
+	return &ServiceSparepart{
+		SparepartRepo: sparepartRepo,
+	}
+}
+
+func (s *ServiceSparepart) Create(userId string, req dto.AddSparepart) (sparepart.Sparepart, error) {
+	data := sparepart.Sparepart{
+		Id:        utils.CreateUUID(),
+		Code:      strings.ToUpper(req.Code),
+		Name:      utils.TitleCase(req.Name),
+		Stock:     req.Stock,
+		Price:     req.Price,
+		CreatedAt: time.Now(),
+		CreatedBy: userId,
+	}
+
+	if err := s.SparepartRepo.Store(data); err != nil {
+		return sparepart.Sparepart{}, err
+	}
+
+	return data, nil
+}
+
+func (s *ServiceSparepart) Fetch(params filter.BaseParams) ([]sparepart.Sparepart, int64, error) {
+	return s.SparepartRepo.Fetch(params)
+}
+
+func (s *ServiceSparepart) GetById(id string) (sparepart.Sparepart, error) {
+	return s.SparepartRepo.GetById(id)
+}
+
+func (s *ServiceSparepart) Update(userId, id string, req dto.UpdateSparepart) (int64, error) {
+	data := map[string]interface{}{
+		"updated_by": userId,
+		"updated_at": time.Now(),
+	}
+	if req.Code != "" {
+		data["code"] = strings.ToUpper(req.Code)
+	}
+	if req.Name != "" {
+		data["name"] = utils.TitleCase(req.Name)
+	}
+	// price and stock are pointers so that an explicit 0 can still be written
+	if req.Price != nil {
+		data["price"] = *req.Price
+	}
+	if req.Stock != nil {
+		data["stock"] = *req.Stock
+	}
+
+	return s.SparepartRepo.Update(sparepart.Sparepart{Id: id}, data)
+}
+
+func (s *ServiceSparepart) Delete(userId, id string) error {
+	data := map[string]interface{}{
+		"deleted_by": userId,
+		"deleted_at": time.Now(),
+	}
+
+	return s.SparepartRepo.Delete(sparepart.Sparepart{Id: id}, data)
+}
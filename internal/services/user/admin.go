@@ -0,0 +1,193 @@
+package user
+
+import (
+	"errors"
+	"slices"
+	"time"
+	"workshop-management/internal/domain/user"
+	"workshop-management/internal/dto"
+	"workshop-management/utils"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	staffRoles      = []string{utils.RoleAdmin, utils.RoleCashier, utils.RoleMechanic}
+	assignableRoles = []string{utils.RoleAdmin, utils.RoleCashier, utils.RoleMechanic, utils.RoleCustomer}
+)
+
+// CreateStaff creates an admin, cashier or mechanic account.
+func (s *ServiceUser) CreateStaff(req dto.CreateStaff) (user.Users, error) {
+	if !slices.Contains(staffRoles, req.Role) {
+		return user.Users{}, user.ErrInvalidRole
+	}
+
+	hashedPwd, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return user.Users{}, err
+	}
+
+	data := user.Users{
+		Id:        utils.CreateUUID(),
+		Name:      req.Name,
+		Phone:     req.Phone,
+		Email:     req.Email,
+		Password:  string(hashedPwd),
+		Role:      req.Role,
+		CreatedAt: time.Now(),
+	}
+
+	if err = s.UserRepo.Store(data); err != nil {
+		return user.Users{}, err
+	}
+
+	return data, nil
+}
+
+// ChangeRole updates the role of a user. The role is part of the JWT claims, so every session of the
+// user is revoked and they have to log in again.
+func (s *ServiceUser) ChangeRole(id, adminId string, req dto.ChangeRole) (user.Users, error) {
+	if !slices.Contains(assignableRoles, req.Role) {
+		return user.Users{}, user.ErrInvalidRole
+	}
+	if id == adminId {
+		return user.Users{}, user.ErrSelfModification
+	}
+
+	data, err := s.UserRepo.GetByID(id)
+	if err != nil {
+		return user.Users{}, err
+	}
+	if data.Role == req.Role {
+		return data, nil
+	}
+
+	now := time.Now()
+	data.Role = req.Role
+	data.UpdatedAt = &now
+	if err = s.UserRepo.Update(data); err != nil {
+		return user.Users{}, err
+	}
+
+	return data, s.revokeAllSessions(data.Id, now.UTC())
+}
+
+// SetActive deactivates or reactivates an account. Deactivation logs the user out everywhere and
+// blocks login and token refresh until the account is reactivated.
+func (s *ServiceUser) SetActive(id, adminId string, active bool) (user.Users, error) {
+	if id == adminId {
+		return user.Users{}, user.ErrSelfModification
+	}
+
+	data, err := s.UserRepo.GetByID(id)
+	if err != nil {
+		return user.Users{}, err
+	}
+	if data.IsActive() == active {
+		return data, nil
+	}
+
+	now := time.Now()
+	data.UpdatedAt = &now
+	if active {
+		data.DeactivatedAt = nil
+		data.DeactivatedBy = ""
+	} else {
+		data.DeactivatedAt = &now
+		data.DeactivatedBy = adminId
+	}
+
+	if err = s.UserRepo.Update(data); err != nil {
+		return user.Users{}, err
+	}
+
+	if !active {
+		return data, s.revokeAllSessions(data.Id, now.UTC())
+	}
+	return data, nil
+}
+
+// ForceResetPassword invalidates the current password and sessions of a user and mails them a reset link.
+func (s *ServiceUser) ForceResetPassword(id string) error {
+	data, err := s.UserRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	// replace the password with an unknown random one so only the reset link can restore access
+	randomPwd, err := generateToken()
+	if err != nil {
+		return err
+	}
+	hashedPwd, err := bcrypt.GenerateFromPassword([]byte(randomPwd), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	data.Password = string(hashedPwd)
+	data.UpdatedAt = &now
+	if err = s.UserRepo.Update(data); err != nil {
+		return err
+	}
+
+	if err = s.revokeAllSessions(data.Id, now.UTC()); err != nil {
+		return err
+	}
+
+	return s.sendResetLink(data)
+}
+
+// EnsureAdmin creates an admin account for req.Email. When the email already belongs to a user, the
+// account is promoted to admin if promote is set and left untouched otherwise. created reports whether
+// a new account was inserted.
+func (s *ServiceUser) EnsureAdmin(req dto.BootstrapAdmin, promote bool) (data user.Users, created bool, err error) {
+	data, err = s.UserRepo.GetByEmail(req.Email)
+	if err == nil {
+		if !promote || data.Role == utils.RoleAdmin {
+			return data, false, nil
+		}
+
+		now := time.Now()
+		data.Role = utils.RoleAdmin
+		data.UpdatedAt = &now
+		if err = s.UserRepo.Update(data); err != nil {
+			return user.Users{}, false, err
+		}
+		return data, false, s.revokeAllSessions(data.Id, now.UTC())
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return user.Users{}, false, err
+	}
+
+	if req.Phone == "" {
+		return user.Users{}, false, errors.New("phone is required to create a new admin")
+	}
+	if len(req.Password) < 8 {
+		return user.Users{}, false, errors.New("password must be at least 8 characters")
+	}
+	if req.Name == "" {
+		req.Name = "Administrator"
+	}
+
+	hashedPwd, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return user.Users{}, false, err
+	}
+
+	data = user.Users{
+		Id:        utils.CreateUUID(),
+		Name:      req.Name,
+		Phone:     req.Phone,
+		Email:     req.Email,
+		Password:  string(hashedPwd),
+		Role:      utils.RoleAdmin,
+		CreatedAt: time.Now(),
+	}
+	if err = s.UserRepo.Store(data); err != nil {
+		return user.Users{}, false, err
+	}
+
+	return data, true, nil
+}
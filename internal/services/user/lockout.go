@@ -0,0 +1,78 @@
+package user
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"workshop-management/internal/domain/auth"
+	"workshop-management/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash is compared against when the email is unknown, so that a login for a missing account
+// takes as long as one with a wrong password.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte(utils.CreateUUID()), bcrypt.DefaultCost)
+	return hash
+})
+
+// accountLockoutPolicy reads LOGIN_MAX_ATTEMPTS (5), LOGIN_ATTEMPT_WINDOW (15 minutes),
+// LOGIN_LOCKOUT_BASE (1 minute) and LOGIN_LOCKOUT_MAX (60 minutes).
+func accountLockoutPolicy() auth.LockoutPolicy {
+	return auth.LockoutPolicy{
+		MaxAttempts: utils.GetEnv("LOGIN_MAX_ATTEMPTS", 5).(int),
+		Window:      time.Duration(utils.GetEnv("LOGIN_ATTEMPT_WINDOW", 15).(int)) * time.Minute,
+		BaseLockout: time.Duration(utils.GetEnv("LOGIN_LOCKOUT_BASE", 1).(int)) * time.Minute,
+		MaxLockout:  time.Duration(utils.GetEnv("LOGIN_LOCKOUT_MAX", 60).(int)) * time.Minute,
+	}
+}
+
+// ipLockoutPolicy is the account policy with a higher threshold, LOGIN_IP_MAX_ATTEMPTS (20), since
+// several users may share one address.
+func ipLockoutPolicy() auth.LockoutPolicy {
+	p := accountLockoutPolicy()
+	p.MaxAttempts = utils.GetEnv("LOGIN_IP_MAX_ATTEMPTS", 20).(int)
+	return p
+}
+
+func accountAttemptKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// checkLockout returns a *auth.LockedError when the account or the client IP is locked.
+func (s *ServiceUser) checkLockout(email, ip string, now time.Time) error {
+	for _, key := range []string{accountAttemptKey(email), ipAttemptKey(ip)} {
+		attempt, err := s.AttemptRepo.Get(key, now)
+		if err != nil {
+			return err
+		}
+		if attempt.IsLocked(now) {
+			return &auth.LockedError{Until: attempt.LockedUntil}
+		}
+	}
+	return nil
+}
+
+// registerFailedLogin counts a failed login against both the account and the client IP. Unknown
+// emails are counted too, so lockouts do not reveal which accounts exist.
+func (s *ServiceUser) registerFailedLogin(email, ip string, now time.Time) error {
+	if _, err := s.AttemptRepo.RegisterFailure(accountAttemptKey(email), accountLockoutPolicy(), now); err != nil {
+		return err
+	}
+	_, err := s.AttemptRepo.RegisterFailure(ipAttemptKey(ip), ipLockoutPolicy(), now)
+	return err
+}
+
+// UnlockAccount clears the failed-login counter and lockout of a user's account.
+func (s *ServiceUser) UnlockAccount(id string) error {
+	data, err := s.UserRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	return s.AttemptRepo.Reset(accountAttemptKey(data.Email))
+}
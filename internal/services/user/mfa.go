@@ -0,0 +1,310 @@
+package user
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"workshop-management/internal/domain/auth"
+	"workshop-management/internal/domain/user"
+	"workshop-management/internal/dto"
+	"workshop-management/internal/policy"
+	"workshop-management/pkg/totp"
+	"workshop-management/utils"
+
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+// beginMfa issues the challenge answered by VerifyMfaLogin. Users whose role requires two-factor
+// authentication but who have not enrolled get a fresh secret to enroll with.
+func (s *ServiceUser) beginMfa(data user.Users) (dto.LoginResult, error) {
+	challenge := dto.MfaChallenge{MfaRequired: true}
+
+	if !data.HasTwoFactor() {
+		setup, err := s.startTotpSetup(&data)
+		if err != nil {
+			return dto.LoginResult{}, err
+		}
+		challenge.Setup = &setup
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return dto.LoginResult{}, err
+	}
+
+	now := time.Now().UTC()
+	m := auth.MfaChallenge{
+		Id:        utils.CreateUUID(),
+		UserId:    data.Id,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(time.Duration(utils.GetEnv("MFA_CHALLENGE_TTL", 5).(int)) * time.Minute),
+		CreatedAt: now,
+	}
+	if err = s.MfaRepo.StoreChallenge(m); err != nil {
+		return dto.LoginResult{}, err
+	}
+
+	challenge.MfaToken, challenge.MfaExpiresAt = token, m.ExpiresAt
+	return dto.LoginResult{MfaChallenge: &challenge}, nil
+}
+
+// VerifyMfaLogin is the second login step: it exchanges the challenge token and a TOTP or recovery code
+// for the session tokens. Wrong codes count towards the login lockout.
+func (s *ServiceUser) VerifyMfaLogin(req dto.LoginMfa, userAgent, ipAddress, logId string) (dto.LoginResult, error) {
+	now := time.Now().UTC()
+	challenge, err := s.MfaRepo.GetChallenge(hashToken(req.MfaToken), now)
+	if err != nil {
+		return dto.LoginResult{}, err
+	}
+
+	data, err := s.UserRepo.GetByID(challenge.UserId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.LoginResult{}, auth.ErrInvalidMfaToken
+		}
+		return dto.LoginResult{}, err
+	}
+	if err = s.checkLockout(data.Email, ipAddress, now); err != nil {
+		return dto.LoginResult{}, err
+	}
+	if !data.IsActive() {
+		return dto.LoginResult{}, user.ErrAccountDisabled
+	}
+
+	var (
+		ok            bool
+		recoveryCodes []string
+	)
+	if data.HasTwoFactor() {
+		ok, err = s.checkSecondFactor(&data, req.Code, now)
+	} else if data.TotpSecret != "" {
+		if ok, err = s.validateTotp(&data, req.Code, now); ok {
+			recoveryCodes, err = s.enableTotp(&data, now)
+		}
+	}
+	if err != nil {
+		return dto.LoginResult{}, err
+	}
+	if !ok {
+		if err = s.registerFailedLogin(data.Email, ipAddress, now); err != nil {
+			return dto.LoginResult{}, err
+		}
+		return dto.LoginResult{}, auth.ErrInvalidMfaCode
+	}
+
+	if err = s.MfaRepo.ConsumeChallenge(challenge.Id, now); err != nil {
+		return dto.LoginResult{}, err
+	}
+	if err = s.AttemptRepo.Reset(accountAttemptKey(data.Email)); err != nil {
+		return dto.LoginResult{}, err
+	}
+
+	token, err := s.createSession(data, userAgent, ipAddress, logId)
+	if err != nil {
+		return dto.LoginResult{}, err
+	}
+
+	return dto.LoginResult{AuthToken: &token, RecoveryCodes: recoveryCodes}, nil
+}
+
+// SetupTotp starts enrollment with a new secret. It only takes effect once EnableTotp confirms a code.
+func (s *ServiceUser) SetupTotp(userId string) (dto.TotpSetup, error) {
+	data, err := s.UserRepo.GetByID(userId)
+	if err != nil {
+		return dto.TotpSetup{}, err
+	}
+	if data.HasTwoFactor() {
+		return dto.TotpSetup{}, auth.ErrMfaAlreadyEnabled
+	}
+
+	return s.startTotpSetup(&data)
+}
+
+// EnableTotp completes enrollment and returns the recovery codes, which are only shown once.
+func (s *ServiceUser) EnableTotp(userId string, req dto.TotpCode) ([]string, error) {
+	data, err := s.UserRepo.GetByID(userId)
+	if err != nil {
+		return nil, err
+	}
+	if data.HasTwoFactor() {
+		return nil, auth.ErrMfaAlreadyEnabled
+	}
+	if data.TotpSecret == "" {
+		return nil, auth.ErrMfaSetupNotStarted
+	}
+
+	now := time.Now().UTC()
+	ok, err := s.validateTotp(&data, req.Code, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, auth.ErrInvalidMfaCode
+	}
+
+	return s.enableTotp(&data, now)
+}
+
+// DisableTotp turns two-factor authentication off after checking a current code. It is refused for roles
+// where two-factor authentication is mandatory.
+func (s *ServiceUser) DisableTotp(userId string, req dto.TotpCode) error {
+	data, err := s.UserRepo.GetByID(userId)
+	if err != nil {
+		return err
+	}
+	if !data.HasTwoFactor() {
+		return auth.ErrMfaNotEnabled
+	}
+	if policy.MfaRequired(data.Role) {
+		return auth.ErrMfaRequiredForRole
+	}
+
+	ok, err := s.checkSecondFactor(&data, req.Code, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return auth.ErrInvalidMfaCode
+	}
+
+	return s.clearTotp(data)
+}
+
+// RegenerateRecoveryCodes replaces every recovery code after checking a current TOTP code.
+func (s *ServiceUser) RegenerateRecoveryCodes(userId string, req dto.TotpCode) ([]string, error) {
+	data, err := s.UserRepo.GetByID(userId)
+	if err != nil {
+		return nil, err
+	}
+	if !data.HasTwoFactor() {
+		return nil, auth.ErrMfaNotEnabled
+	}
+
+	ok, err := s.validateTotp(&data, req.Code, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, auth.ErrInvalidMfaCode
+	}
+
+	return s.replaceRecoveryCodes(data.Id)
+}
+
+// ResetTotp removes the two-factor enrollment of a user who lost both their authenticator and recovery
+// codes. Users whose role requires it enroll again at their next login.
+func (s *ServiceUser) ResetTotp(id, adminId string) error {
+	if id == adminId {
+		return user.ErrSelfModification
+	}
+
+	data, err := s.UserRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.clearTotp(data)
+}
+
+func (s *ServiceUser) startTotpSetup(data *user.Users) (dto.TotpSetup, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return dto.TotpSetup{}, err
+	}
+
+	data.TotpSecret, data.TotpLastStep = secret, 0
+	if err = s.UserRepo.Update(*data); err != nil {
+		return dto.TotpSetup{}, err
+	}
+
+	return dto.TotpSetup{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(secret, utils.GetEnv("TOTP_ISSUER", "Workshop Management").(string), data.Email),
+	}, nil
+}
+
+func (s *ServiceUser) enableTotp(data *user.Users, now time.Time) ([]string, error) {
+	data.TotpEnabledAt = &now
+	if err := s.UserRepo.Update(*data); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(data.Id)
+}
+
+func (s *ServiceUser) clearTotp(data user.Users) error {
+	data.TotpSecret, data.TotpEnabledAt, data.TotpLastStep = "", nil, 0
+	if err := s.UserRepo.Update(data); err != nil {
+		return err
+	}
+
+	return s.MfaRepo.ReplaceRecoveryCodes(data.Id, nil)
+}
+
+// checkSecondFactor accepts a TOTP code or an unused recovery code.
+func (s *ServiceUser) checkSecondFactor(data *user.Users, code string, now time.Time) (bool, error) {
+	if ok, err := s.validateTotp(data, code, now); ok || err != nil {
+		return ok, err
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, nil
+	}
+	return s.MfaRepo.ConsumeRecoveryCode(data.Id, hashToken(normalized), now)
+}
+
+func (s *ServiceUser) replaceRecoveryCodes(userId string) ([]string, error) {
+	now := time.Now().UTC()
+	codes := make([]string, 0, recoveryCodeCount)
+	rows := make([]auth.RecoveryCode, 0, recoveryCodeCount)
+
+	for range recoveryCodeCount {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		code := hex.EncodeToString(raw)
+
+		codes = append(codes, code[:5]+"-"+code[5:])
+		rows = append(rows, auth.RecoveryCode{
+			Id:        utils.CreateUUID(),
+			UserId:    userId,
+			CodeHash:  hashToken(code),
+			CreatedAt: now,
+		})
+	}
+
+	if err := s.MfaRepo.ReplaceRecoveryCodes(userId, rows); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// validateTotp checks code against the user's secret, allowing one step of clock drift, and records the
+// step with a conditional update so a code is only accepted once, even by concurrent requests.
+func (s *ServiceUser) validateTotp(data *user.Users, code string, now time.Time) (bool, error) {
+	step, ok := totp.Validate(data.TotpSecret, strings.TrimSpace(code), now, 1)
+	if !ok || step <= data.TotpLastStep {
+		return false, nil
+	}
+
+	claimed, err := s.UserRepo.ClaimTotpStep(data.Id, step)
+	if err != nil || !claimed {
+		return false, err
+	}
+	data.TotpLastStep = step
+	return true, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(code) != 10 {
+		return ""
+	}
+	return code
+}
@@ -0,0 +1,151 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+	"workshop-management/internal/domain/auth"
+	"workshop-management/internal/domain/user"
+	"workshop-management/internal/dto"
+	"workshop-management/utils"
+)
+
+// RefreshTokenTTL is the absolute lifetime of a session, JWT_REFRESH_TTL hours (default 720 = 30 days).
+func RefreshTokenTTL() time.Duration {
+	return time.Duration(utils.GetEnv("JWT_REFRESH_TTL", 720).(int)) * time.Hour
+}
+
+// createSession starts a new session (token family) and issues its first access and refresh tokens.
+func (s *ServiceUser) createSession(data user.Users, userAgent, ipAddress, logId string) (dto.AuthToken, error) {
+	refreshToken, err := generateToken()
+	if err != nil {
+		return dto.AuthToken{}, err
+	}
+
+	now := time.Now().UTC()
+	session := auth.Session{
+		Id:         utils.CreateUUID(),
+		UserId:     data.Id,
+		UserAgent:  truncate(userAgent, 255),
+		IpAddress:  truncate(ipAddress, 45),
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(RefreshTokenTTL()),
+	}
+	token := auth.RefreshToken{
+		Id:        utils.CreateUUID(),
+		SessionId: session.Id,
+		TokenHash: hashToken(refreshToken),
+		CreatedAt: now,
+	}
+
+	if err = s.SessionRepo.Create(session, token); err != nil {
+		return dto.AuthToken{}, err
+	}
+
+	return s.issueTokens(data, session.Id, refreshToken, logId)
+}
+
+// Refresh rotates a refresh token: the presented token is consumed and a new access/refresh pair is
+// returned. Re-using a consumed token revokes the whole session.
+func (s *ServiceUser) Refresh(req dto.RefreshToken, logId string) (dto.AuthToken, error) {
+	refreshToken, err := generateToken()
+	if err != nil {
+		return dto.AuthToken{}, err
+	}
+
+	next := auth.RefreshToken{
+		Id:        utils.CreateUUID(),
+		TokenHash: hashToken(refreshToken),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	session, err := s.SessionRepo.Rotate(hashToken(req.RefreshToken), next, next.CreatedAt)
+	if errors.Is(err, auth.ErrRefreshTokenReused) {
+		if revokeErr := s.BlacklistRepo.RevokeSessions([]auth.Session{session}); revokeErr != nil {
+			return dto.AuthToken{}, revokeErr
+		}
+	}
+	if err != nil {
+		return dto.AuthToken{}, err
+	}
+
+	data, err := s.UserRepo.GetByID(session.UserId)
+	if err != nil {
+		return dto.AuthToken{}, err
+	}
+	if !data.IsActive() {
+		return dto.AuthToken{}, user.ErrAccountDisabled
+	}
+
+	return s.issueTokens(data, session.Id, refreshToken, logId)
+}
+
+func (s *ServiceUser) issueTokens(data user.Users, sessionId, refreshToken, logId string) (dto.AuthToken, error) {
+	expiresAt := time.Now().Add(utils.AccessTokenTTL())
+	token, err := utils.GenerateJwt(&data, sessionId, logId)
+	if err != nil {
+		return dto.AuthToken{}, err
+	}
+
+	return dto.AuthToken{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// GetSessions lists the active sessions of the user, flagging the one making the request.
+func (s *ServiceUser) GetSessions(userId, currentSessionId string) ([]auth.Session, error) {
+	sessions, err := s.SessionRepo.GetActiveByUserId(userId, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].Id == currentSessionId
+	}
+
+	return sessions, nil
+}
+
+func (s *ServiceUser) RevokeSession(userId, sessionId string) (int64, error) {
+	revoked, err := s.SessionRepo.Revoke(userId, sessionId, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(revoked)), s.BlacklistRepo.RevokeSessions(revoked)
+}
+
+// RevokeOtherSessions logs the user out everywhere except the current session.
+func (s *ServiceUser) RevokeOtherSessions(userId, currentSessionId string) error {
+	revoked, err := s.SessionRepo.RevokeAll(userId, currentSessionId, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	return s.BlacklistRepo.RevokeSessions(revoked)
+}
+
+func generateToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(v string, max int) string {
+	if r := []rune(v); len(r) > max {
+		return string(r[:max])
+	}
+	return v
+}
@@ -1,29 +1,58 @@
 package user
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
 	"workshop-management/internal/domain/auth"
 	"workshop-management/internal/domain/user"
 	"workshop-management/internal/dto"
+	"workshop-management/internal/policy"
 	"workshop-management/pkg/filter"
+	"workshop-management/pkg/logger"
+	"workshop-management/pkg/mailer"
+	"workshop-management/pkg/sms"
 	"workshop-management/utils"
 
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type ServiceUser struct {
 	UserRepo      user.RepoUser
 	BlacklistRepo auth.RepoAuth
+	SessionRepo   auth.RepoSession
+	ResetRepo     auth.RepoPasswordReset
+	AttemptRepo   auth.RepoLoginAttempt
+	Mailer        mailer.Mailer
+
+	VerificationRepo auth.RepoVerification
+	SMS              sms.Sender
+	MfaRepo          auth.RepoMfa
 }
 
-func NewUserService(userRepo user.RepoUser, blacklistRepo auth.RepoAuth) *ServiceUser {
+func NewUserService(userRepo user.RepoUser, blacklistRepo auth.RepoAuth, sessionRepo auth.RepoSession, resetRepo auth.RepoPasswordReset, attemptRepo auth.RepoLoginAttempt, mail mailer.Mailer, verificationRepo auth.RepoVerification, smsSender sms.Sender, mfaRepo auth.RepoMfa) *ServiceUser {
 	return &ServiceUser{
-		UserRepo:      userRepo,
-		BlacklistRepo: blacklistRepo,
+		UserRepo:         userRepo,
+		BlacklistRepo:    blacklistRepo,
+		SessionRepo:      sessionRepo,
+		ResetRepo:        resetRepo,
+		AttemptRepo:      attemptRepo,
+		Mailer:           mail,
+		VerificationRepo: verificationRepo,
+		SMS:              smsSender,
+		MfaRepo:          mfaRepo,
 	}
 }
 
+// WithContext returns a copy of the service whose user writes are attributed to the caller in ctx.
+func (s *ServiceUser) WithContext(ctx context.Context) *ServiceUser {
+	c := *s
+	c.UserRepo = s.UserRepo.WithContext(ctx)
+	return &c
+}
+
 func (s *ServiceUser) RegisterUser(req dto.UserRegister) (user.Users, error) {
 	hashedPwd, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -44,32 +73,71 @@ func (s *ServiceUser) RegisterUser(req dto.UserRegister) (user.Users, error) {
 		return user.Users{}, err
 	}
 
+	// the account exists either way; the user can request a new code if delivery failed
+	if err = s.sendVerificationCode(data, ""); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[ServiceUser][RegisterUser][%s]; sendVerificationCode; Error: %+v", data.Id, err))
+	}
+
 	return data, nil
 }
 
-func (s *ServiceUser) LoginUser(req dto.Login, logId string) (string, error) {
+// LoginUser checks the credentials and starts a session. Unknown emails and wrong passwords both return
+// user.ErrInvalidCredentials and count towards the account and IP lockouts. Users with two-factor
+// authentication, or whose role requires it, get a challenge for VerifyMfaLogin instead of tokens.
+func (s *ServiceUser) LoginUser(req dto.Login, userAgent, ipAddress, logId string) (dto.LoginResult, error) {
+	now := time.Now().UTC()
+	if err := s.checkLockout(req.Email, ipAddress, now); err != nil {
+		return dto.LoginResult{}, err
+	}
+
+	hash := dummyHash()
 	data, err := s.UserRepo.GetByEmail(req.Email)
-	if err != nil {
-		return "", err
+	if err == nil {
+		hash = []byte(data.Password)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.LoginResult{}, err
 	}
 
-	if err = bcrypt.CompareHashAndPassword([]byte(data.Password), []byte(req.Password)); err != nil {
-		return "", err
+	if bcrypt.CompareHashAndPassword(hash, []byte(req.Password)) != nil || err != nil {
+		if err = s.registerFailedLogin(req.Email, ipAddress, now); err != nil {
+			return dto.LoginResult{}, err
+		}
+		return dto.LoginResult{}, user.ErrInvalidCredentials
 	}
 
-	token, err := utils.GenerateJwt(&data, logId)
-	if err != nil {
-		return "", err
+	if !data.IsActive() {
+		return dto.LoginResult{}, user.ErrAccountDisabled
+	}
+	// the counter is only cleared once the second factor is verified, so a known password cannot be
+	// used to reset it between code guesses
+	if data.HasTwoFactor() || policy.MfaRequired(data.Role) {
+		return s.beginMfa(data)
+	}
+	if err = s.AttemptRepo.Reset(accountAttemptKey(req.Email)); err != nil {
+		return dto.LoginResult{}, err
 	}
 
-	return token, nil
+	token, err := s.createSession(data, userAgent, ipAddress, logId)
+	if err != nil {
+		return dto.LoginResult{}, err
+	}
+	return dto.LoginResult{AuthToken: &token}, nil
 }
 
-func (s *ServiceUser) LogoutUser(token string) error {
+// LogoutUser blacklists the access token until it expires and revokes the session (and its refresh token) it belongs to.
+func (s *ServiceUser) LogoutUser(token string, claims map[string]interface{}) error {
+	now := time.Now().UTC()
+	expiresAt := now.Add(utils.AccessTokenTTL())
+	if exp, ok := claims["exp"].(float64); ok {
+		expiresAt = time.Unix(int64(exp), 0).UTC()
+	}
+
 	blacklist := auth.Blacklist{
 		ID:        utils.CreateUUID(),
+		Jti:       utils.InterfaceString(claims["jti"]),
 		Token:     token,
-		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
 	}
 
 	err := s.BlacklistRepo.Store(blacklist)
@@ -77,6 +145,16 @@ func (s *ServiceUser) LogoutUser(token string) error {
 		return err
 	}
 
+	if sessionId := utils.InterfaceString(claims["sid"]); sessionId != "" {
+		revoked, err := s.SessionRepo.Revoke(utils.InterfaceString(claims["user_id"]), sessionId, now)
+		if err != nil {
+			return err
+		}
+		if err = s.BlacklistRepo.RevokeSessions(revoked); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -102,12 +180,15 @@ func (s *ServiceUser) Update(id string, req dto.UserUpdate) (user.Users, error)
 		data.Name = req.Name
 	}
 
-	if req.Phone != "" {
+	// a changed contact has to be verified again
+	if req.Phone != "" && req.Phone != data.Phone {
 		data.Phone = req.Phone
+		data.VerifiedAt = nil
 	}
 
-	if req.Email != "" {
+	if req.Email != "" && req.Email != data.Email {
 		data.Email = req.Email
+		data.VerifiedAt = nil
 	}
 
 	if err = s.UserRepo.Update(data); err != nil {
@@ -148,3 +229,90 @@ func (s *ServiceUser) ChangePassword(id string, req dto.ChangePassword) (user.Us
 func (s *ServiceUser) Delete(id string) error {
 	return s.UserRepo.Delete(id)
 }
+
+// ForgotPassword issues a single-use reset token and mails the reset link. Unknown emails are ignored
+// so the endpoint does not reveal which accounts exist.
+func (s *ServiceUser) ForgotPassword(req dto.ForgotPassword) error {
+	data, err := s.UserRepo.GetByEmail(req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !data.IsActive() {
+		return nil
+	}
+
+	return s.sendResetLink(data)
+}
+
+// sendResetLink issues a reset token for the user and mails the link.
+func (s *ServiceUser) sendResetLink(data user.Users) error {
+	token, err := generateToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	ttl := time.Duration(utils.GetEnv("PASSWORD_RESET_TTL", 30).(int)) * time.Minute
+	reset := auth.PasswordReset{
+		Id:        utils.CreateUUID(),
+		UserId:    data.Id,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if err = s.ResetRepo.Store(reset); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s?token=%s", utils.GetEnv("PASSWORD_RESET_URL", "http://localhost:5173/reset-password").(string), token)
+	body := fmt.Sprintf("Hi %s,\n\nUse the link below to reset your password. It expires in %d minutes and can only be used once.\n\n%s\n\nIf you did not request a password reset, you can ignore this email.", data.Name, int(ttl.Minutes()), link)
+
+	return s.Mailer.Send(data.Email, "Reset your password", body)
+}
+
+// ResetPassword consumes a reset token, sets the new password and revokes every existing session of the user.
+func (s *ServiceUser) ResetPassword(req dto.ResetPassword) error {
+	now := time.Now().UTC()
+	reset, err := s.ResetRepo.Consume(hashToken(req.Token), now)
+	if err != nil {
+		return err
+	}
+
+	data, err := s.UserRepo.GetByID(reset.UserId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return auth.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	hashedPwd, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	data.Password = string(hashedPwd)
+	data.UpdatedAt = &now
+	if err = s.UserRepo.Update(data); err != nil {
+		return err
+	}
+
+	return s.revokeAllSessions(data.Id, now)
+}
+
+// revokeAllSessions logs the user out everywhere: sessions can no longer be refreshed and access tokens
+// issued before now are rejected.
+func (s *ServiceUser) revokeAllSessions(userId string, now time.Time) error {
+	revoked, err := s.SessionRepo.RevokeAll(userId, "", now)
+	if err != nil {
+		return err
+	}
+	if err = s.BlacklistRepo.RevokeSessions(revoked); err != nil {
+		return err
+	}
+
+	return s.BlacklistRepo.RevokeUser(userId, now)
+}
@@ -0,0 +1,124 @@
+package user
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+	"workshop-management/internal/domain/auth"
+	"workshop-management/internal/domain/user"
+	"workshop-management/internal/dto"
+	"workshop-management/utils"
+
+	"gorm.io/gorm"
+)
+
+// RequestVerification sends a new one-time code to the user's email or phone, VERIFICATION_CHANNEL
+// (default email) when no channel is given. Requests are limited to one per
+// VERIFICATION_RESEND_COOLDOWN seconds (default 60).
+func (s *ServiceUser) RequestVerification(userId string, req dto.RequestVerification) error {
+	data, err := s.UserRepo.GetByID(userId)
+	if err != nil {
+		return err
+	}
+	if data.IsVerified() {
+		return auth.ErrAlreadyVerified
+	}
+
+	latest, err := s.VerificationRepo.GetLatest(userId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	cooldown := time.Duration(utils.GetEnv("VERIFICATION_RESEND_COOLDOWN", 60).(int)) * time.Second
+	if err == nil && time.Now().UTC().Before(latest.CreatedAt.Add(cooldown)) {
+		return auth.ErrVerificationTooSoon
+	}
+
+	return s.sendVerificationCode(data, req.Channel)
+}
+
+// ConfirmVerification marks the user as verified when the code matches their latest code and was sent
+// to their current email or phone. Each code allows VERIFICATION_MAX_ATTEMPTS guesses (default 5).
+func (s *ServiceUser) ConfirmVerification(userId string, req dto.ConfirmVerification) (user.Users, error) {
+	now := time.Now().UTC()
+	code, err := s.VerificationRepo.Consume(userId, hashVerificationCode(userId, req.Code), utils.GetEnv("VERIFICATION_MAX_ATTEMPTS", 5).(int), now)
+	if err != nil {
+		return user.Users{}, err
+	}
+
+	data, err := s.UserRepo.GetByID(userId)
+	if err != nil {
+		return user.Users{}, err
+	}
+	if data.IsVerified() {
+		return data, nil
+	}
+	// the contact changed after the code was sent
+	if code.Target != verificationTarget(data, code.Channel) {
+		return user.Users{}, auth.ErrInvalidVerificationCode
+	}
+
+	data.VerifiedAt = &now
+	if err = s.UserRepo.Update(data); err != nil {
+		return user.Users{}, err
+	}
+
+	return data, nil
+}
+
+// sendVerificationCode issues a code for the user and delivers it over channel.
+func (s *ServiceUser) sendVerificationCode(data user.Users, channel string) error {
+	if channel == "" {
+		channel = utils.GetEnv("VERIFICATION_CHANNEL", auth.ChannelEmail).(string)
+	}
+	if channel != auth.ChannelEmail && channel != auth.ChannelSMS {
+		return fmt.Errorf("unsupported verification channel: %s", channel)
+	}
+
+	code, err := generateVerificationCode()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	ttl := time.Duration(utils.GetEnv("VERIFICATION_CODE_TTL", 15).(int)) * time.Minute
+	m := auth.VerificationCode{
+		Id:        utils.CreateUUID(),
+		UserId:    data.Id,
+		Channel:   channel,
+		Target:    verificationTarget(data, channel),
+		CodeHash:  hashVerificationCode(data.Id, code),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if err = s.VerificationRepo.Store(m); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", code, int(ttl.Minutes()))
+	if channel == auth.ChannelSMS {
+		return s.SMS.Send(m.Target, message)
+	}
+	return s.Mailer.Send(m.Target, "Verify your account", fmt.Sprintf("Hi %s,\n\n%s\n\nIf you did not create an account, you can ignore this email.", data.Name, message))
+}
+
+func verificationTarget(data user.Users, channel string) string {
+	if channel == auth.ChannelSMS {
+		return data.Phone
+	}
+	return data.Email
+}
+
+func generateVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// hashVerificationCode salts the short code with the user id so equal codes hash differently.
+func hashVerificationCode(userId, code string) string {
+	return hashToken(userId + ":" + code)
+}
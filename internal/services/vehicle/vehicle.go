@@ -1,10 +1,12 @@
 package vehicle
 
 import (
+	"context"
 	"strings"
 	"time"
 	"workshop-management/internal/domain/vehicle"
 	"workshop-management/internal/dto"
+	"workshop-management/internal/policy"
 	"workshop-management/pkg/filter"
 	"workshop-management/utils"
 )
@@ -19,6 +21,13 @@ func NewVehicleService(vehicleRepo vehicle.RepoVehicle) *ServiceVehicle {
 	}
 }
 
+// WithContext returns a copy of the service recording ctx as the origin of its writes in the audit log.
+func (s *ServiceVehicle) WithContext(ctx context.Context) *ServiceVehicle {
+	c := *s
+	c.VehicleRepo = s.VehicleRepo.WithContext(ctx)
+	return &c
+}
+
 func (s *ServiceVehicle) Create(userId string, req dto.AddVehicle) (vehicle.Vehicle, error) {
 	data := vehicle.Vehicle{
 		Id:           utils.CreateUUID(),
@@ -38,31 +47,57 @@ func (s *ServiceVehicle) Create(userId string, req dto.AddVehicle) (vehicle.Vehi
 	return data, nil
 }
 
-func (s *ServiceVehicle) GetById(id string) (vehicle.Vehicle, error) {
-	return s.VehicleRepo.GetById(id)
+// GetById returns the vehicle when the actor owns it (or is staff); foreign vehicles are reported as not found.
+func (s *ServiceVehicle) GetById(actor policy.Actor, id string) (vehicle.Vehicle, error) {
+	data, err := s.VehicleRepo.GetById(id)
+	if err != nil {
+		return vehicle.Vehicle{}, err
+	}
+
+	if err = actor.Authorize(data.UserId); err != nil {
+		return vehicle.Vehicle{}, err
+	}
+
+	return data, nil
 }
 
-func (s *ServiceVehicle) Fetch(params filter.BaseParams) ([]vehicle.Vehicle, int64, error) {
+// Fetch lists vehicles, restricted to the actor's own vehicles unless the actor is staff.
+func (s *ServiceVehicle) Fetch(actor policy.Actor, params filter.BaseParams) ([]vehicle.Vehicle, int64, error) {
+	if ownerId := actor.OwnerScope(); ownerId != "" {
+		if params.Filters == nil {
+			params.Filters = map[string]interface{}{}
+		}
+		params.Filters["user_id"] = ownerId
+	}
+
 	return s.VehicleRepo.Fetch(params)
 }
 
-func (s *ServiceVehicle) Update(id, userId string, req dto.UpdateVehicle) (int64, error) {
+func (s *ServiceVehicle) Update(actor policy.Actor, id string, req dto.UpdateVehicle) (int64, error) {
+	if _, err := s.GetById(actor, id); err != nil {
+		return 0, err
+	}
+
 	data := vehicle.Vehicle{
 		Brand:        strings.ToUpper(req.Brand),
 		Model:        utils.TitleCase(req.Model),
 		Year:         req.Year,
 		Color:        utils.TitleCase(req.Color),
 		LicensePlate: strings.ToUpper(req.LicensePlate),
-		UpdatedBy:    userId,
+		UpdatedBy:    actor.UserId,
 		UpdatedAt:    time.Now(),
 	}
 
 	return s.VehicleRepo.Update(vehicle.Vehicle{Id: id}, data)
 }
 
-func (s *ServiceVehicle) Delete(id, userId string) error {
+func (s *ServiceVehicle) Delete(actor policy.Actor, id string) error {
+	if _, err := s.GetById(actor, id); err != nil {
+		return err
+	}
+
 	data := map[string]interface{}{
-		"deleted_by": userId,
+		"deleted_by": actor.UserId,
 		"deleted_at": time.Now(),
 	}
 
@@ -0,0 +1,121 @@
+package vehicle
+
+import (
+	"errors"
+	"testing"
+	"workshop-management/internal/domain/vehicle"
+	"workshop-management/internal/dto"
+	"workshop-management/internal/policy"
+	"workshop-management/pkg/filter"
+	"workshop-management/utils"
+
+	"gorm.io/gorm"
+)
+
+// fakeRepo keeps vehicles in memory and applies the user_id filter like the SQL repository.
+type fakeRepo struct {
+	vehicle.RepoVehicle
+	rows []vehicle.Vehicle
+}
+
+func (r *fakeRepo) GetById(id string) (vehicle.Vehicle, error) {
+	for _, v := range r.rows {
+		if v.Id == id {
+			return v, nil
+		}
+	}
+	return vehicle.Vehicle{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRepo) Fetch(params filter.BaseParams) ([]vehicle.Vehicle, int64, error) {
+	var ret []vehicle.Vehicle
+	for _, v := range r.rows {
+		if userId, ok := params.Filters["user_id"]; ok && userId != v.UserId {
+			continue
+		}
+		ret = append(ret, v)
+	}
+	return ret, int64(len(ret)), nil
+}
+
+func (r *fakeRepo) Update(m vehicle.Vehicle, data interface{}) (int64, error) {
+	return 1, nil
+}
+
+func (r *fakeRepo) Delete(m vehicle.Vehicle, data interface{}) error {
+	return nil
+}
+
+var (
+	customer = policy.Actor{UserId: "u1", Role: utils.RoleCustomer}
+	cashier  = policy.Actor{UserId: "c1", Role: utils.RoleCashier}
+)
+
+func newService() *ServiceVehicle {
+	return NewVehicleService(&fakeRepo{rows: []vehicle.Vehicle{
+		{Id: "v1", UserId: "u1"},
+		{Id: "v2", UserId: "u2"},
+		{Id: "v3", UserId: "u1"},
+	}})
+}
+
+func TestFetchScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   policy.Actor
+		filters map[string]interface{}
+		want    []string
+	}{
+		{"customer sees only own vehicles", customer, map[string]interface{}{}, []string{"v1", "v3"}},
+		{"customer cannot widen the scope with a filter", customer, map[string]interface{}{"user_id": "u2"}, []string{"v1", "v3"}},
+		{"staff see every vehicle", cashier, map[string]interface{}{}, []string{"v1", "v2", "v3"}},
+		{"staff may filter by owner", cashier, map[string]interface{}{"user_id": "u2"}, []string{"v2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, total, err := newService().Fetch(tt.actor, filter.BaseParams{Filters: tt.filters})
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if int(total) != len(tt.want) || len(rows) != len(tt.want) {
+				t.Fatalf("Fetch() returned %d rows, want %d", len(rows), len(tt.want))
+			}
+			for i, v := range rows {
+				if v.Id != tt.want[i] {
+					t.Fatalf("Fetch()[%d] = %s, want %s", i, v.Id, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOwnership(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   policy.Actor
+		id      string
+		wantErr error
+	}{
+		{"customer on own vehicle", customer, "v1", nil},
+		{"customer on foreign vehicle", customer, "v2", gorm.ErrRecordNotFound},
+		{"customer on missing vehicle", customer, "v9", gorm.ErrRecordNotFound},
+		{"staff on any vehicle", cashier, "v2", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newService()
+
+			if _, err := s.GetById(tt.actor, tt.id); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetById() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := s.Update(tt.actor, tt.id, dto.UpdateVehicle{}); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := s.Delete(tt.actor, tt.id); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Delete() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,27 +1,50 @@
 package workorder
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 	"workshop-management/internal/domain/booking"
+	"workshop-management/internal/domain/invoice"
+	"workshop-management/internal/domain/user"
 	"workshop-management/internal/domain/workorder"
 	"workshop-management/internal/dto"
+	"workshop-management/internal/policy"
 	"workshop-management/pkg/filter"
+	"workshop-management/pkg/logger"
 	"workshop-management/utils"
+
+	"gorm.io/gorm"
 )
 
 type ServiceWorkOrder struct {
 	WorkOrderRepo workorder.RepoWorkOrder
 	BookingRepo   booking.RepoBooking
+	UserRepo      user.RepoUser
+	InvoiceSvc    invoice.Service
 }
 
-func NewServiceWorkOrder(workOrderRepo workorder.RepoWorkOrder, bookingRepo booking.RepoBooking) *ServiceWorkOrder {
+func NewServiceWorkOrder(workOrderRepo workorder.RepoWorkOrder, bookingRepo booking.RepoBooking, userRepo user.RepoUser, invoiceSvc invoice.Service) *ServiceWorkOrder {
 	return &ServiceWorkOrder{
 		WorkOrderRepo: workOrderRepo,
 		BookingRepo:   bookingRepo,
+		UserRepo:      userRepo,
+		InvoiceSvc:    invoiceSvc,
 	}
 }
 
+// WithContext returns a copy of the service bound to ctx. Bookings updated alongside a work order are
+// attributed to the same request.
+func (s *ServiceWorkOrder) WithContext(ctx context.Context) workorder.Service {
+	c := *s
+	c.WorkOrderRepo = s.WorkOrderRepo.WithContext(ctx)
+	c.BookingRepo = s.BookingRepo.WithContext(ctx)
+	c.UserRepo = s.UserRepo.WithContext(ctx)
+	return &c
+}
+
 func (s *ServiceWorkOrder) CreateFromBooking(bookingId, userId string) (workorder.WorkOrder, error) {
 	bookingData, err := s.BookingRepo.GetById(bookingId)
 	if err != nil {
@@ -44,22 +67,37 @@ func (s *ServiceWorkOrder) CreateFromBooking(bookingId, userId string) (workorde
 		CreatedBy:  userId,
 	}
 
-	// create WO detail (service breakdown from booking)
+	// create WO detail (service breakdown from booking), at the accepted quote's prices when there is one
 	var woServices []workorder.SvcWorkOrder
 	for _, bs := range bookingData.Services {
+		price, ok := bookingData.Quote.UnitPrice(bs.Id)
+		if !ok {
+			price = bs.Price
+		}
+
 		woServices = append(woServices, workorder.SvcWorkOrder{
 			Id:          utils.CreateUUID(),
 			WorkOrderId: woID,
 			ServiceId:   bs.Id,
 			ServiceName: bs.Name,
-			Price:       bs.Price,
+			Price:       price,
+			Quantity:    1,
 			Status:      utils.StsOpen,
 			CreatedAt:   time.Now(),
 			CreatedBy:   userId,
 		})
 	}
 
-	if err = s.WorkOrderRepo.Create(wo, woServices); err != nil {
+	history := workorder.StatusHistory{
+		Id:          utils.CreateUUID(),
+		WorkOrderId: woID,
+		ToStatus:    utils.StsOpen,
+		Reason:      "created from booking",
+		ChangedAt:   wo.CreatedAt,
+		ChangedBy:   userId,
+	}
+
+	if err = s.WorkOrderRepo.Create(wo, woServices, history); err != nil {
 		return workorder.WorkOrder{}, err
 	}
 	wo.Services = woServices
@@ -73,27 +111,152 @@ func (s *ServiceWorkOrder) AssignMechanic(req dto.AssignMechanic, workOrderId, u
 		return 0, err
 	}
 
-	if wo.Status != utils.StsOpen {
-		return 0, errors.New("work order can't be assigned")
+	// a mechanic can be swapped at any point until the work order is closed
+	if workorder.IsFinal(wo.Status) {
+		return 0, workorder.ErrNotAssignable
+	}
+
+	if wo.MechanicId != nil && *wo.MechanicId == req.MechanicID {
+		return 0, workorder.ErrAlreadyAssigned
 	}
 
+	mechanic, err := s.UserRepo.GetByID(req.MechanicID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, workorder.ErrInvalidMechanic
+		}
+		return 0, err
+	}
+	if mechanic.Role != utils.RoleMechanic {
+		return 0, workorder.ErrInvalidMechanic
+	}
+
+	now := time.Now()
 	data := map[string]interface{}{
-		"mechanic_id": req.MechanicID,
-		"status":      utils.StsOnProgress,
-		"updated_at":  time.Now(),
+		"mechanic_id": mechanic.Id,
+		"updated_at":  now,
 		"updated_by":  userId,
 	}
+	if wo.Status == utils.StsOpen {
+		data["status"] = utils.StsOnProgress
+	}
 
-	return s.WorkOrderRepo.Update(workorder.WorkOrder{Id: workOrderId}, data)
+	assignment := workorder.Assignment{
+		Id:                 utils.CreateUUID(),
+		WorkOrderId:        workOrderId,
+		MechanicId:         mechanic.Id,
+		PreviousMechanicId: wo.MechanicId,
+		Reason:             req.Reason,
+		AssignedAt:         now,
+		AssignedBy:         userId,
+	}
+
+	// the workload is counted inside the assignment transaction, so concurrent assignments cannot exceed it
+	maxActive := utils.GetEnv("MECHANIC_MAX_ACTIVE_WORK_ORDERS", 5).(int)
+	rows, err := s.WorkOrderRepo.Assign(workorder.WorkOrder{Id: workOrderId}, data, assignment, maxActive)
+	if err != nil || rows == 0 {
+		return rows, err
+	}
+	s.syncBooking(wo, utils.StsOnProgress, userId)
+
+	return rows, nil
 }
 
 func (s *ServiceWorkOrder) GetById(id string) (workorder.WorkOrder, error) {
 	return s.WorkOrderRepo.GetById(id)
 }
 
-func (s *ServiceWorkOrder) UpdateStatus(workOrderId, status, userId string) (int64, error) {
+func (s *ServiceWorkOrder) GetAssignments(workOrderId string) ([]workorder.Assignment, error) {
+	if _, err := s.WorkOrderRepo.GetById(workOrderId); err != nil {
+		return nil, err
+	}
+
+	return s.WorkOrderRepo.GetAssignments(workOrderId)
+}
+
+// GetStatusHistory returns the status transitions of a work order, oldest first. Customers only see
+// the history of their own work orders.
+func (s *ServiceWorkOrder) GetStatusHistory(workOrderId, userId, role string) ([]workorder.StatusHistory, error) {
+	wo, err := s.WorkOrderRepo.GetById(workOrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = (policy.Actor{UserId: userId, Role: role}).Authorize(wo.CustomerId); err != nil {
+		return nil, err
+	}
+
+	return s.WorkOrderRepo.GetStatusHistory(workOrderId)
+}
+
+func (s *ServiceWorkOrder) UpdateStatus(req dto.UpdateStatus, workOrderId, userId, role string) (int64, error) {
+	wo, err := s.WorkOrderRepo.GetById(workOrderId)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = checkAssignee(wo, userId, role); err != nil {
+		return 0, err
+	}
+
+	status := strings.ToLower(strings.TrimSpace(req.Status))
+	if err = workorder.CanTransition(wo.Status, status, role); err != nil {
+		return 0, err
+	}
+	if status == utils.StsCompleted && !workorder.ServicesFinished(wo.Services) {
+		return 0, workorder.ErrServicesPending
+	}
+
+	data := utils.UpdateStatus(userId, status)
+	// the repository only applies the change while the work order still has the validated status
+	history := workorder.StatusHistory{
+		Id:          utils.CreateUUID(),
+		WorkOrderId: workOrderId,
+		FromStatus:  &wo.Status,
+		ToStatus:    status,
+		Reason:      req.Reason,
+		ChangedAt:   time.Now(),
+		ChangedBy:   userId,
+	}
+
+	var rows int64
+	if status == utils.StsCancelled {
+		// cancelling releases the consumed parts back to stock
+		rows, err = s.WorkOrderRepo.Cancel(workorder.WorkOrder{Id: workOrderId, UpdatedBy: userId}, data, history)
+	} else {
+		rows, err = s.WorkOrderRepo.UpdateStatus(workorder.WorkOrder{Id: workOrderId}, data, history)
+	}
+	if err != nil || rows == 0 {
+		return rows, err
+	}
+	s.syncBooking(wo, status, userId)
+
+	if status == utils.StsCompleted && s.InvoiceSvc != nil && utils.GetEnv("INVOICE_AUTO_GENERATE", true).(bool) {
+		// the status change is already persisted; a failed invoice can still be generated manually
+		if _, err = s.InvoiceSvc.CreateFromWorkOrder(workOrderId, userId); err != nil {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[ServiceWorkOrder][UpdateStatus][%s]; InvoiceSvc.CreateFromWorkOrder; Error: %+v", workOrderId, err))
+		}
+	}
+
+	return rows, nil
+}
+
+func (s *ServiceWorkOrder) UpdateNotes(req dto.UpdateWorkOrderNotes, workOrderId, userId, role string) (int64, error) {
+	wo, err := s.WorkOrderRepo.GetById(workOrderId)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = checkAssignee(wo, userId, role); err != nil {
+		return 0, err
+	}
+
+	if workorder.IsFinal(wo.Status) {
+		return 0, workorder.ErrNotEditable
+	}
+
 	data := map[string]interface{}{
-		"status":     status,
+		"notes":      req.Notes,
 		"updated_at": time.Now(),
 		"updated_by": userId,
 	}
@@ -101,6 +264,134 @@ func (s *ServiceWorkOrder) UpdateStatus(workOrderId, status, userId string) (int
 	return s.WorkOrderRepo.Update(workorder.WorkOrder{Id: workOrderId}, data)
 }
 
+func (s *ServiceWorkOrder) UpdateServiceStatus(workOrderId, svcId, status, userId, role string) (int64, error) {
+	wo, err := s.WorkOrderRepo.GetById(workOrderId)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = checkAssignee(wo, userId, role); err != nil {
+		return 0, err
+	}
+
+	// jobs can only be worked on once the work order has started and until it is closed
+	if wo.Status == utils.StsOpen || workorder.IsFinal(wo.Status) {
+		return 0, workorder.ErrNotEditable
+	}
+
+	svc, err := s.WorkOrderRepo.GetServiceById(workOrderId, svcId)
+	if err != nil {
+		return 0, err
+	}
+
+	status = strings.ToLower(strings.TrimSpace(status))
+	if err = workorder.CanTransitionService(svc.Status, status, role); err != nil {
+		return 0, err
+	}
+
+	return s.WorkOrderRepo.UpdateService(svc, utils.UpdateStatus(userId, status))
+}
+
+// syncBooking mirrors the work order lifecycle onto the booking it was created from.
+func (s *ServiceWorkOrder) syncBooking(wo workorder.WorkOrder, status, userId string) {
+	switch status {
+	case utils.StsOnProgress:
+		// only the first start moves the booking, not a return from waiting_parts or quality_check
+		if wo.Status != utils.StsOpen {
+			return
+		}
+	case utils.StsCompleted, utils.StsCancelled:
+	default:
+		return
+	}
+
+	history := booking.StatusHistory{
+		Id:        utils.CreateUUID(),
+		BookingId: wo.BookingId,
+		ToStatus:  status,
+		Reason:    fmt.Sprintf("work order %s", status),
+		ChangedAt: time.Now(),
+		ChangedBy: userId,
+	}
+	if _, err := s.BookingRepo.UpdateStatus(booking.Booking{Id: wo.BookingId}, utils.UpdateStatus(userId, status), history); err != nil {
+		logger.WriteLog(logger.LogLevelError, fmt.Sprintf("[ServiceWorkOrder][syncBooking][%s]; BookingRepo.UpdateStatus; Error: %+v", wo.Id, err))
+	}
+}
+
 func (s *ServiceWorkOrder) Fetch(params filter.BaseParams) ([]workorder.WorkOrder, int64, error) {
 	return s.WorkOrderRepo.Fetch(params)
 }
+
+func (s *ServiceWorkOrder) AddPart(req dto.AddWorkOrderPart, workOrderId, userId string) (workorder.PartWorkOrder, error) {
+	wo, err := s.WorkOrderRepo.GetById(workOrderId)
+	if err != nil {
+		return workorder.PartWorkOrder{}, err
+	}
+
+	if !partsEditable(wo.Status) {
+		return workorder.PartWorkOrder{}, workorder.ErrNotEditable
+	}
+
+	now := time.Now()
+	part := workorder.PartWorkOrder{
+		Id:          utils.CreateUUID(),
+		WorkOrderId: workOrderId,
+		SparepartId: req.SparepartID,
+		Quantity:    req.Quantity,
+		CreatedAt:   now,
+		CreatedBy:   userId,
+		UpdatedAt:   now,
+		UpdatedBy:   userId,
+	}
+
+	return s.WorkOrderRepo.AddPart(part)
+}
+
+func (s *ServiceWorkOrder) UpdatePart(req dto.UpdateWorkOrderPart, workOrderId, partId, userId string) (workorder.PartWorkOrder, error) {
+	wo, err := s.WorkOrderRepo.GetById(workOrderId)
+	if err != nil {
+		return workorder.PartWorkOrder{}, err
+	}
+
+	if !partsEditable(wo.Status) {
+		return workorder.PartWorkOrder{}, workorder.ErrNotEditable
+	}
+
+	part := workorder.PartWorkOrder{
+		Id:          partId,
+		WorkOrderId: workOrderId,
+		Quantity:    req.Quantity,
+		UpdatedAt:   time.Now(),
+		UpdatedBy:   userId,
+	}
+
+	return s.WorkOrderRepo.UpdatePartQuantity(part)
+}
+
+func (s *ServiceWorkOrder) RemovePart(workOrderId, partId, userId string) error {
+	wo, err := s.WorkOrderRepo.GetById(workOrderId)
+	if err != nil {
+		return err
+	}
+
+	if !partsEditable(wo.Status) {
+		return workorder.ErrNotEditable
+	}
+
+	return s.WorkOrderRepo.RemovePart(workorder.PartWorkOrder{Id: partId, WorkOrderId: workOrderId, DeletedBy: userId})
+}
+
+// checkAssignee limits mechanics to the work orders assigned to them; other roles are not restricted.
+func checkAssignee(wo workorder.WorkOrder, userId, role string) error {
+	if role != utils.RoleMechanic {
+		return nil
+	}
+	if wo.MechanicId == nil || *wo.MechanicId != userId {
+		return workorder.ErrNotAssigned
+	}
+	return nil
+}
+
+func partsEditable(status string) bool {
+	return !workorder.IsFinal(status)
+}
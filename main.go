@@ -10,7 +10,12 @@ import (
 	"strings"
 	"time"
 	_ "workshop-management/docs"
+	"workshop-management/infrastructure/cache"
 	"workshop-management/infrastructure/database"
+	"workshop-management/internal/cli"
+	"workshop-management/internal/jobs"
+	auditRepo "workshop-management/internal/repositories/audit"
+	authRepo "workshop-management/internal/repositories/auth"
 	"workshop-management/internal/router"
 	"workshop-management/pkg/config"
 	"workshop-management/pkg/logger"
@@ -84,6 +89,17 @@ func main() {
 	flag.Parse()
 	logger.WriteLog(logger.LogLevelInfo, "APP: "+appName+"; PORT: "+port)
 
+	// e.g. `workshop-management jwt keygen --alg EdDSA --dir keys`
+	if args := flag.Args(); len(args) > 0 && args[0] == "jwt" {
+		if err = cli.RunJwt(args[1:], os.Stdout); err != nil {
+			log.Fatalf("jwt: %s", err)
+		}
+		return
+	}
+
+	_, err = utils.JwtKeys()
+	FailOnError(err, "Failed to load JWT keys")
+
 	//Load app config
 	confID := config.GetAppConf("CONFIG_ID", "", nil)
 	logger.WriteLog(logger.LogLevelDebug, fmt.Sprintf("ConfigID: %s", confID))
@@ -95,11 +111,50 @@ func main() {
 	FailOnError(err, "Failed to open db")
 	defer sqlDb.Close()
 
+	err = routes.DB.Use(auditRepo.Plugin{})
+	FailOnError(err, "Failed to register audit callbacks")
+
+	// every instance must read revocations from the same store, so a Redis blacklist is required to start
+	blacklistInRedis := strings.ToLower(utils.GetEnv("AUTH_BLACKLIST_STORE", "db").(string)) == "redis"
+	if blacklistInRedis || strings.ToLower(utils.GetEnv("LOGIN_ATTEMPT_STORE", "memory").(string)) == "redis" {
+		if routes.Redis, err = cache.ConnRedis(); err != nil {
+			if blacklistInRedis {
+				FailOnError(err, "Failed to connect to Redis for the token blacklist")
+			}
+			logger.WriteLog(logger.LogLevelError, "Redis unavailable, login attempts fall back to memory; Error: "+err.Error())
+		} else {
+			defer routes.Redis.Close()
+		}
+	}
+
+	// e.g. `workshop-management admin create --email admin@example.com --phone 0812345678`
+	if args := flag.Args(); len(args) > 0 && args[0] == "admin" {
+		if err = cli.RunAdmin(routes.DB, routes.BlacklistRepo(), args[1:], os.Stdout); err != nil {
+			sqlDb.Close()
+			if routes.Redis != nil {
+				routes.Redis.Close()
+			}
+			log.Fatalf("admin: %s", err)
+		}
+		return
+	}
+
+	if err = cli.SeedAdmin(routes.DB, routes.BlacklistRepo()); err != nil {
+		logger.WriteLog(logger.LogLevelError, "SeedAdmin; Error: "+err.Error())
+	}
+
+	jobs.StartAuthCleanup(authRepo.NewCleanupRepo(routes.DB), time.Duration(utils.GetEnv("AUTH_CLEANUP_INTERVAL", 60).(int))*time.Minute)
+
 	routes.UserRoutes()
 	routes.VehicleRoutes()
 	routes.ServiceRoutes()
+	routes.SparepartRoutes()
 	routes.BookingRoutes()
 	routes.WorkOrderRoutes()
+	routes.InvoiceRoutes()
+	routes.PaymentRoutes()
+	routes.PermissionRoutes()
+	routes.AuditRoutes()
 
 	err = routes.App.Run(fmt.Sprintf(":%s", port))
 	FailOnError(err, "Failed run service")
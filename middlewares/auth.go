@@ -1,11 +1,12 @@
 package middlewares
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
 	"slices"
+	"time"
 	"workshop-management/internal/domain/auth"
+	"workshop-management/internal/domain/permission"
 	"workshop-management/pkg/logger"
 	"workshop-management/pkg/messages"
 	"workshop-management/pkg/response"
@@ -13,18 +14,19 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
 // Middleware struct to hold dependencies
 type Middleware struct {
-	BlacklistRepo auth.RepoAuth
+	BlacklistRepo  auth.RepoAuth
+	PermissionRepo permission.RepoPermission
 }
 
 // NewMiddleware creates a new middleware with its dependencies
-func NewMiddleware(blacklistRepo auth.RepoAuth) *Middleware {
+func NewMiddleware(blacklistRepo auth.RepoAuth, permissionRepo permission.RepoPermission) *Middleware {
 	return &Middleware{
-		BlacklistRepo: blacklistRepo,
+		BlacklistRepo:  blacklistRepo,
+		PermissionRepo: permissionRepo,
 	}
 }
 
@@ -51,9 +53,9 @@ func (m *Middleware) AuthMiddleware() gin.HandlerFunc {
 		logPrefix += fmt.Sprintf("[%s][%s]", utils.InterfaceString(dataJWT["jti"]), utils.InterfaceString(dataJWT["user_id"]))
 
 		// Check if token is blacklisted
-		_, err = m.BlacklistRepo.GetByToken(tokenString)
-		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; blacklistRepo.GetByToken; Error: %+v", logPrefix, err))
+		blacklisted, err := m.BlacklistRepo.IsBlacklisted(utils.InterfaceString(dataJWT["jti"]))
+		if err != nil {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; blacklistRepo.IsBlacklisted; Error: %+v", logPrefix, err))
 			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
 			res.Error = err.Error()
 			ctx.AbortWithStatusJSON(http.StatusInternalServerError, res)
@@ -61,7 +63,7 @@ func (m *Middleware) AuthMiddleware() gin.HandlerFunc {
 		}
 
 		//the token is valid but has been logged out
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
+		if blacklisted {
 			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Invalid Token: %s; Error: token is blacklisted;", logPrefix, tokenString))
 			res := response.Response(http.StatusUnauthorized, messages.MsgFail, logId, nil)
 			res.Error = "Please login and try again"
@@ -69,6 +71,42 @@ func (m *Middleware) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// tokens issued before a user-wide revocation (e.g. password reset) are no longer valid
+		revokedAt, err := m.BlacklistRepo.GetRevokedAt(utils.InterfaceString(dataJWT["user_id"]))
+		if err != nil {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; blacklistRepo.GetRevokedAt; Error: %+v", logPrefix, err))
+			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+			res.Error = err.Error()
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, res)
+			return
+		}
+		if iat, ok := dataJWT["iat"].(float64); ok && !revokedAt.IsZero() && int64(iat) < revokedAt.Unix() {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Invalid Token: %s; Error: token is revoked;", logPrefix, tokenString))
+			res := response.Response(http.StatusUnauthorized, messages.MsgFail, logId, nil)
+			res.Error = "Please login and try again"
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			return
+		}
+
+		// the session the token belongs to may have been logged out from another device
+		if sessionId := utils.InterfaceString(dataJWT["sid"]); sessionId != "" {
+			revoked, err := m.BlacklistRepo.IsSessionRevoked(sessionId, time.Now().UTC())
+			if err != nil {
+				logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; blacklistRepo.IsSessionRevoked; Error: %+v", logPrefix, err))
+				res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+				res.Error = err.Error()
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, res)
+				return
+			}
+			if revoked {
+				logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; Invalid Token: %s; Error: session is revoked;", logPrefix, tokenString))
+				res := response.Response(http.StatusUnauthorized, messages.MsgFail, logId, nil)
+				res.Error = "Please login and try again"
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+				return
+			}
+		}
+
 		ctx.Set(utils.CtxKeyAuthData, dataJWT)
 		ctx.Set("token", tokenString)
 
@@ -117,3 +155,50 @@ func (m *Middleware) RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// RequirePermission allows the request when the caller's role has been granted the permission. Admins
+// always pass, so editing the mapping can never lock them out.
+func (m *Middleware) RequirePermission(name string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var (
+			logId     uuid.UUID
+			logPrefix string
+		)
+
+		logId = utils.GenerateLogId(ctx)
+		logPrefix = fmt.Sprintf("[%s][RequirePermission][%s]", logId, name)
+
+		userRole := utils.InterfaceString(utils.GetAuthData(ctx)["role"])
+		if userRole == "" {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; there is no role user", logPrefix))
+			res := response.Response(http.StatusForbidden, messages.MsgDenied, logId, nil)
+			res.Error = "there is no role user"
+			ctx.AbortWithStatusJSON(http.StatusForbidden, res)
+			return
+		}
+
+		if userRole == utils.RoleAdmin {
+			ctx.Next()
+			return
+		}
+
+		granted, err := m.PermissionRepo.HasPermission(userRole, name)
+		if err != nil {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; PermissionRepo.HasPermission; Error: %+v", logPrefix, err))
+			res := response.Response(http.StatusInternalServerError, messages.MsgFail, logId, nil)
+			res.Error = err.Error()
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, res)
+			return
+		}
+
+		if !granted {
+			logger.WriteLog(logger.LogLevelError, fmt.Sprintf("%s; User with role '%s' lacks the permission;", logPrefix, userRole))
+			res := response.Response(http.StatusForbidden, messages.MsgDenied, logId, nil)
+			res.Error = response.Errors{Code: http.StatusForbidden, Message: messages.AccessDenied}
+			ctx.AbortWithStatusJSON(http.StatusForbidden, res)
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS uq_invoices_work_order;
+DROP TABLE IF EXISTS invoice_items;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS invoice_items (
+    id UUID PRIMARY KEY,
+    invoice_id UUID NOT NULL,
+    item_type VARCHAR(20) NOT NULL,
+    reference_id UUID NOT NULL,
+    description VARCHAR(150) NOT NULL,
+    quantity INT NOT NULL DEFAULT 1,
+    unit_price NUMERIC(12,2) NOT NULL,
+    subtotal NUMERIC(12,2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_invoice FOREIGN KEY (invoice_id) REFERENCES invoices(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_invoices_work_order ON invoices (work_order_id) WHERE deleted_at IS NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS work_order_assignments;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS work_order_assignments (
+    id UUID PRIMARY KEY,
+    work_order_id UUID NOT NULL,
+    mechanic_id UUID NOT NULL,
+    previous_mechanic_id UUID NULL,
+    reason VARCHAR(255),
+    assigned_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    assigned_by VARCHAR(50) NOT NULL,
+    CONSTRAINT fk_work_order FOREIGN KEY (work_order_id) REFERENCES work_orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_mechanic FOREIGN KEY (mechanic_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_work_order_assignments_work_order ON work_order_assignments (work_order_id);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS token_revocations (
+    user_id UUID PRIMARY KEY,
+    revoked_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
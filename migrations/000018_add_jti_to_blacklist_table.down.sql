@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_blacklist_expires_at;
+DROP INDEX IF EXISTS idx_blacklist_jti;
+ALTER TABLE blacklist DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE blacklist DROP COLUMN IF EXISTS jti;
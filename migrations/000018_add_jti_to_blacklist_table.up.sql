@@ -0,0 +1,21 @@
+ALTER TABLE blacklist ADD COLUMN IF NOT EXISTS jti VARCHAR(64);
+ALTER TABLE blacklist ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+-- backfill from the JWT payload (base64url encoded, second segment of the token)
+WITH payload AS (
+    SELECT id, convert_from(decode(rpad(translate(split_part(token, '.', 2), '-_', '+/'),
+               ((length(split_part(token, '.', 2)) + 3) / 4) * 4, '='), 'base64'), 'UTF8')::json AS claims
+    FROM blacklist
+    WHERE jti IS NULL
+)
+UPDATE blacklist b
+SET jti = p.claims ->> 'jti',
+    expires_at = to_timestamp((p.claims ->> 'exp')::bigint) AT TIME ZONE 'UTC'
+FROM payload p
+WHERE b.id = p.id;
+
+UPDATE blacklist SET expires_at = created_at + INTERVAL '24 hours' WHERE expires_at IS NULL;
+ALTER TABLE blacklist ALTER COLUMN expires_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_blacklist_jti ON blacklist (jti);
+CREATE INDEX IF NOT EXISTS idx_blacklist_expires_at ON blacklist (expires_at);
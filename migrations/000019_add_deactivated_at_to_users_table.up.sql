@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_by VARCHAR(50) NULL;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(50) NOT NULL,
+    permission VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_by VARCHAR(50),
+    PRIMARY KEY (role, permission)
+);
+
+-- mirrors the role lists previously hardcoded in the router; admin implicitly holds every permission
+INSERT INTO role_permissions (role, permission) VALUES
+    ('cashier', 'user:read'),
+    ('cashier', 'workorder:create'),
+    ('cashier', 'workorder:assign'),
+    ('cashier', 'workorder:update'),
+    ('cashier', 'workorder:parts'),
+    ('cashier', 'invoice:read'),
+    ('cashier', 'invoice:create'),
+    ('cashier', 'payment:read'),
+    ('cashier', 'payment:create'),
+    ('mechanic', 'workorder:update'),
+    ('mechanic', 'workorder:queue'),
+    ('customer', 'vehicle:write')
+ON CONFLICT DO NOTHING;
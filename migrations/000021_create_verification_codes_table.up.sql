@@ -0,0 +1,16 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verified_at TIMESTAMP NULL;
+
+CREATE TABLE IF NOT EXISTS verification_codes (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    channel VARCHAR(10) NOT NULL,
+    target VARCHAR(100) NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_codes_user ON verification_codes (user_id, created_at);
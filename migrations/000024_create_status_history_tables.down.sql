@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS work_order_status_history;
+DROP TABLE IF EXISTS booking_status_history;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS booking_status_history (
+    id UUID PRIMARY KEY,
+    booking_id UUID NOT NULL,
+    from_status VARCHAR(50) NULL,
+    to_status VARCHAR(50) NOT NULL,
+    reason VARCHAR(255),
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    changed_by VARCHAR(50) NOT NULL,
+    CONSTRAINT fk_booking FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_status_history_booking ON booking_status_history (booking_id, changed_at);
+
+CREATE TABLE IF NOT EXISTS work_order_status_history (
+    id UUID PRIMARY KEY,
+    work_order_id UUID NOT NULL,
+    from_status VARCHAR(20) NULL,
+    to_status VARCHAR(20) NOT NULL,
+    reason VARCHAR(255),
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    changed_by VARCHAR(50) NOT NULL,
+    CONSTRAINT fk_work_order FOREIGN KEY (work_order_id) REFERENCES work_orders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_work_order_status_history_work_order ON work_order_status_history (work_order_id, changed_at);
+
+-- seed the current status of existing rows so every history starts somewhere
+INSERT INTO booking_status_history (id, booking_id, to_status, reason, changed_at, changed_by)
+SELECT gen_random_uuid(), id, status, 'recorded before status history', COALESCE(updated_at, created_at), COALESCE(NULLIF(updated_by, ''), user_id::text)
+FROM bookings;
+
+INSERT INTO work_order_status_history (id, work_order_id, to_status, reason, changed_at, changed_by)
+SELECT gen_random_uuid(), id, status, 'recorded before status history', COALESCE(updated_at, created_at), COALESCE(NULLIF(updated_by, ''), created_by)
+FROM work_orders;
@@ -0,0 +1,7 @@
+ALTER TABLE invoices
+    DROP COLUMN IF EXISTS tax,
+    DROP COLUMN IF EXISTS discount,
+    DROP COLUMN IF EXISTS subtotal;
+
+DROP TABLE IF EXISTS booking_quote_items;
+DROP TABLE IF EXISTS booking_quotes;
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS booking_quotes (
+    id UUID PRIMARY KEY,
+    booking_id UUID NOT NULL,
+    vehicle_id UUID NOT NULL,
+    subtotal NUMERIC(12,2) NOT NULL,
+    discount NUMERIC(12,2) NOT NULL DEFAULT 0,
+    tax_rate NUMERIC(5,2) NOT NULL DEFAULT 0,
+    tax NUMERIC(12,2) NOT NULL DEFAULT 0,
+    total NUMERIC(12,2) NOT NULL,
+    accepted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    accepted_by VARCHAR(50) NOT NULL,
+    CONSTRAINT fk_booking FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE,
+    CONSTRAINT uq_booking_quote UNIQUE (booking_id)
+);
+
+CREATE TABLE IF NOT EXISTS booking_quote_items (
+    id UUID PRIMARY KEY,
+    quote_id UUID NOT NULL,
+    service_id UUID NOT NULL,
+    service_name VARCHAR(100) NOT NULL,
+    unit_price NUMERIC(12,2) NOT NULL,
+    quantity INT NOT NULL DEFAULT 1,
+    subtotal NUMERIC(12,2) NOT NULL,
+    CONSTRAINT fk_quote FOREIGN KEY (quote_id) REFERENCES booking_quotes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_quote_items_quote ON booking_quote_items (quote_id);
+
+ALTER TABLE invoices
+    ADD COLUMN IF NOT EXISTS subtotal NUMERIC(12,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS discount NUMERIC(12,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax NUMERIC(12,2) NOT NULL DEFAULT 0;
+
+-- invoices issued so far had neither discount nor tax
+UPDATE invoices SET subtotal = total;
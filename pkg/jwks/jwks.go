@@ -0,0 +1,281 @@
+package jwks
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MinRSABits is the smallest RSA modulus accepted for signing keys.
+const MinRSABits = 2048
+
+var (
+	ErrUnknownKey   = errors.New("unknown signing key")
+	ErrNoSigningKey = errors.New("no private key available for signing")
+)
+
+// Key is a single signing or verification key. Keys loaded from a public key file (or kept only to
+// verify tokens issued before a rotation) have no signing key.
+type Key struct {
+	Kid    string
+	Method jwt.SigningMethod
+	sign   interface{}
+	verify interface{}
+}
+
+// CanSign reports whether the key holds private material.
+func (k *Key) CanSign() bool {
+	return k.sign != nil
+}
+
+// SignKey is the value passed to jwt.Token.SignedString.
+func (k *Key) SignKey() interface{} {
+	return k.sign
+}
+
+// VerifyKey is the value returned from a jwt.Keyfunc.
+func (k *Key) VerifyKey() interface{} {
+	return k.verify
+}
+
+// KeySet holds every key tokens may be verified with and the one new tokens are signed with.
+type KeySet struct {
+	keys    map[string]*Key
+	signing *Key
+}
+
+// NewHMAC returns a key set with a single shared secret and no kid, matching tokens issued before
+// asymmetric keys were introduced.
+func NewHMAC(secret []byte) *KeySet {
+	key := &Key{Method: jwt.SigningMethodHS256, sign: secret, verify: secret}
+	return &KeySet{keys: map[string]*Key{"": key}, signing: key}
+}
+
+// LoadDir reads every *.pem file in dir. The file name without the extension (and without a ".pub"
+// suffix for public keys) is the kid. Private keys may be PKCS#8 (RSA or Ed25519) or PKCS#1 (RSA);
+// public keys are PKIX. New tokens are signed with signingKid, or with the private key whose kid sorts
+// last when signingKid is empty, so date-based kids (e.g. 2026-10-17) rotate by adding a file.
+func LoadDir(dir, signingKid string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	set := &KeySet{keys: map[string]*Key{}}
+	for _, path := range paths {
+		kid := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".pem"), ".pub")
+		if _, ok := set.keys[kid]; ok {
+			return nil, fmt.Errorf("duplicate kid %q in %s", kid, dir)
+		}
+
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := ParsePEM(kid, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		set.keys[kid] = key
+	}
+
+	if signingKid == "" {
+		kids := set.Kids()
+		for i := len(kids) - 1; i >= 0; i-- {
+			if set.keys[kids[i]].CanSign() {
+				signingKid = kids[i]
+				break
+			}
+		}
+	}
+
+	signing, ok := set.keys[signingKid]
+	if !ok || !signing.CanSign() {
+		return nil, fmt.Errorf("%w: kid %q in %s", ErrNoSigningKey, signingKid, dir)
+	}
+	set.signing = signing
+
+	return set, nil
+}
+
+// ParsePEM decodes a private or public key and derives its signing method from the key type.
+func ParsePEM(kid string, raw []byte) (*Key, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var (
+		parsed interface{}
+		err    error
+	)
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < MinRSABits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", MinRSABits)
+		}
+		return &Key{Kid: kid, Method: jwt.SigningMethodRS256, sign: k, verify: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		if k.N.BitLen() < MinRSABits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", MinRSABits)
+		}
+		return &Key{Kid: kid, Method: jwt.SigningMethodRS256, verify: k}, nil
+	case ed25519.PrivateKey:
+		return &Key{Kid: kid, Method: jwt.SigningMethodEdDSA, sign: k, verify: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &Key{Kid: kid, Method: jwt.SigningMethodEdDSA, verify: k}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+}
+
+// AddLegacyHMAC accepts tokens without a kid signed with the shared secret, so tokens issued before
+// switching to asymmetric keys stay valid until they expire. The secret is never used for signing.
+func (s *KeySet) AddLegacyHMAC(secret []byte) {
+	if _, ok := s.keys[""]; ok {
+		return
+	}
+	s.keys[""] = &Key{Method: jwt.SigningMethodHS256, verify: secret}
+}
+
+// SigningKey returns the key new tokens are signed with.
+func (s *KeySet) SigningKey() *Key {
+	return s.signing
+}
+
+// Lookup returns the key identified by kid ("" for tokens without a kid header).
+func (s *KeySet) Lookup(kid string) (*Key, error) {
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// Kids returns the identifiers of every key in the set, sorted.
+func (s *KeySet) Kids() []string {
+	kids := make([]string, 0, len(s.keys))
+	for kid := range s.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	return kids
+}
+
+// Keyfunc resolves the verification key of a token from its kid header and rejects tokens whose
+// algorithm does not match the key.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, err := s.Lookup(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.VerifyKey(), nil
+}
+
+// JSONWebKey is the public part of a key as defined by RFC 7517.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS publishes the public keys of the set. Shared secrets are never included.
+func (s *KeySet) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+
+	for _, kid := range s.Kids() {
+		key := s.keys[kid]
+		jwk := JSONWebKey{Kid: kid, Use: "sig", Alg: key.Method.Alg()}
+
+		switch pub := key.VerifyKey().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = encode(pub.N.Bytes())
+			jwk.E = encode(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = encode(pub)
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+// GenerateKey creates a private key for alg ("RS256" or "EdDSA") encoded as a PKCS#8 PEM block.
+func GenerateKey(alg string, random io.Reader) ([]byte, error) {
+	var (
+		key crypto.Signer
+		err error
+	)
+	switch alg {
+	case jwt.SigningMethodRS256.Alg():
+		key, err = rsa.GenerateKey(random, MinRSABits)
+	case jwt.SigningMethodEdDSA.Alg():
+		_, key, err = ed25519.GenerateKey(random)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q, use RS256 or EdDSA", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
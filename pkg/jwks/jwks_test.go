@@ -0,0 +1,234 @@
+package jwks
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeKey(t *testing.T, dir, name string, raw []byte) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func generate(t *testing.T, alg string) []byte {
+	t.Helper()
+	raw, err := GenerateKey(alg, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func publicPEM(t *testing.T, pub interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func sign(t *testing.T, key *Key) string {
+	t.Helper()
+	token := jwt.NewWithClaims(key.Method, jwt.MapClaims{"sub": "u1"})
+	token.Header["kid"] = key.Kid
+	signed, err := token.SignedString(key.SignKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestLoadDirSigningKey(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "2026-01-01.pem", generate(t, "EdDSA"))
+	writeKey(t, dir, "2026-06-01.pem", generate(t, "EdDSA"))
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKey(t, dir, "2027-01-01.pub.pem", publicPEM(t, pub))
+
+	set, err := LoadDir(dir, "")
+	if err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	if kid := set.SigningKey().Kid; kid != "2026-06-01" {
+		t.Fatalf("signing kid = %s, want the last private kid 2026-06-01", kid)
+	}
+
+	verifyOnly, err := set.Lookup("2027-01-01")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if verifyOnly.CanSign() {
+		t.Fatal("key loaded from a .pub file can sign")
+	}
+
+	if _, err = LoadDir(dir, "2027-01-01"); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("LoadDir() with a public signing kid error = %v, want %v", err, ErrNoSigningKey)
+	}
+}
+
+func TestLoadDirRejects(t *testing.T) {
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(small)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := generate(t, "EdDSA")
+	parsed, err := ParsePEM("k", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  string
+	}{
+		{"duplicate kid", map[string][]byte{"k.pem": key, "k.pub.pem": publicPEM(t, parsed.VerifyKey())}, "duplicate kid"},
+		{"small RSA private key", map[string][]byte{"k.pem": pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})}, "at least 2048 bits"},
+		{"small RSA public key", map[string][]byte{"k.pem": key, "old.pub.pem": publicPEM(t, &small.PublicKey)}, "at least 2048 bits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, raw := range tt.files {
+				writeKey(t, dir, name, raw)
+			}
+
+			if _, err := LoadDir(dir, ""); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("LoadDir() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyfuncAfterRotation(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "2026-01-01.pem", generate(t, "RS256"))
+
+	before, err := LoadDir(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := sign(t, before.SigningKey())
+
+	writeKey(t, dir, "2026-06-01.pem", generate(t, "EdDSA"))
+	after, err := LoadDir(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := after.SigningKey().Kid; kid != "2026-06-01" {
+		t.Fatalf("signing kid after rotation = %s, want 2026-06-01", kid)
+	}
+
+	for name, signed := range map[string]string{"old kid": old, "new kid": sign(t, after.SigningKey())} {
+		if _, err := jwt.Parse(signed, after.Keyfunc); err != nil {
+			t.Fatalf("%s: Parse() error = %v", name, err)
+		}
+	}
+}
+
+func TestKeyfuncLegacyHMAC(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "2026-01-01.pem", generate(t, "RS256"))
+	set, err := LoadDir(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := []byte("legacy-shared-secret")
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "u1"}).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = jwt.Parse(legacy, set.Keyfunc); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Parse() without legacy secret error = %v, want %v", err, ErrUnknownKey)
+	}
+
+	set.AddLegacyHMAC(secret)
+	if _, err = jwt.Parse(legacy, set.Keyfunc); err != nil {
+		t.Fatalf("Parse() with legacy secret error = %v", err)
+	}
+	if set.SigningKey().Method.Alg() != jwt.SigningMethodRS256.Alg() {
+		t.Fatal("legacy secret became the signing key")
+	}
+}
+
+func TestKeyfuncAlgorithmConfusion(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "rsa.pem", generate(t, "RS256"))
+	set, err := LoadDir(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.AddLegacyHMAC([]byte("legacy-shared-secret"))
+
+	// an attacker signs with HS256 using the published RSA public key as the shared secret
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "admin"})
+	forged.Header["kid"] = "rsa"
+	signed, err := forged.SignedString(publicPEM(t, set.SigningKey().VerifyKey()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = jwt.Parse(signed, set.Keyfunc); err == nil || !strings.Contains(err.Error(), "unexpected signing method") {
+		t.Fatalf("Parse() of an HS256 token carrying an RSA kid error = %v, want an unexpected signing method", err)
+	}
+}
+
+func TestJWKSExcludesSharedSecret(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "rsa.pem", generate(t, "RS256"))
+	writeKey(t, dir, "ed.pem", generate(t, "EdDSA"))
+	set, err := LoadDir(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := "legacy-shared-secret"
+	set.AddLegacyHMAC([]byte(secret))
+
+	published := set.JWKS()
+	if len(published.Keys) != 2 {
+		t.Fatalf("JWKS() has %d keys, want 2", len(published.Keys))
+	}
+	for _, key := range published.Keys {
+		if key.Kid == "" || key.Alg == jwt.SigningMethodHS256.Alg() {
+			t.Fatalf("JWKS() published the shared secret entry %+v", key)
+		}
+	}
+
+	raw, err := json.Marshal(published)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leak := range []string{secret, encode([]byte(secret))} {
+		if strings.Contains(string(raw), leak) {
+			t.Fatal("JWKS() output contains the shared secret")
+		}
+	}
+
+	if hmacOnly := NewHMAC([]byte(secret)).JWKS(); len(hmacOnly.Keys) != 0 {
+		t.Fatalf("JWKS() of an HMAC key set has %d keys, want 0", len(hmacOnly.Keys))
+	}
+}
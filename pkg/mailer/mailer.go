@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"workshop-management/pkg/sink"
+	"workshop-management/utils"
+)
+
+// Mailer delivers plain-text emails.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NewMailer returns the mailer selected by MAIL_DRIVER ("log" by default, or "file").
+// The log mailer only prints email bodies when MAIL_LOG_BODY is set to true.
+func NewMailer() Mailer {
+	switch strings.ToLower(utils.GetEnv("MAIL_DRIVER", "log").(string)) {
+	case "file":
+		return &FileMailer{Dir: utils.GetEnv("MAIL_DIR", "tmp/mail").(string)}
+	default:
+		return &LogMailer{ShowBody: utils.GetEnv("MAIL_LOG_BODY", false).(bool)}
+	}
+}
+
+// LogMailer writes emails to the application log. Bodies carry reset links and verification codes,
+// so they are redacted unless ShowBody is set, which is meant for local development only.
+type LogMailer struct {
+	ShowBody bool
+}
+
+func (m *LogMailer) Send(to, subject, body string) error {
+	sink.Log{Name: "Mailer", Reveal: m.ShowBody}.Write(fmt.Sprintf("To: %s; Subject: %s", to, subject), body)
+	return nil
+}
+
+// FileMailer stores each email as a .eml file in Dir, so tests and developers can pick them up.
+type FileMailer struct {
+	Dir string
+}
+
+func (m *FileMailer) Send(to, subject, body string) error {
+	content := fmt.Sprintf("To: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n", to, subject, time.Now().Format(time.RFC1123Z), body)
+	return sink.File{Dir: m.Dir, Ext: ".eml"}.Write(content)
+}
@@ -0,0 +1,42 @@
+// Package sink holds the development transports shared by the mailer and the SMS sender: one writes
+// messages to the application log, the other stores every message as a file.
+package sink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+	"workshop-management/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// Log writes messages to the application log under the [Name] prefix. Bodies are replaced by their
+// length unless Reveal is set, as they usually carry secrets such as links and one-time codes.
+type Log struct {
+	Name   string
+	Reveal bool
+}
+
+func (l Log) Write(header, body string) {
+	if !l.Reveal {
+		body = fmt.Sprintf("[redacted, %d bytes]", len(body))
+	}
+	logger.WriteLog(logger.LogLevelInfo, fmt.Sprintf("[%s]; %s; Body: %s", l.Name, header, body))
+}
+
+// File stores each message in its own file in Dir, named after the current time with extension Ext.
+type File struct {
+	Dir string
+	Ext string
+}
+
+func (f File) Write(content string) error {
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s_%s%s", time.Now().Format("20060102T150405"), uuid.NewString(), f.Ext)
+	return os.WriteFile(filepath.Join(f.Dir, name), []byte(content), 0o600)
+}
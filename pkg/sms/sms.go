@@ -0,0 +1,46 @@
+package sms
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"workshop-management/pkg/sink"
+	"workshop-management/utils"
+)
+
+// Sender delivers plain-text SMS messages.
+type Sender interface {
+	Send(to, message string) error
+}
+
+// NewSender returns the sender selected by SMS_DRIVER ("log" by default, or "file").
+// The log sender only prints message texts when SMS_LOG_MESSAGE is set to true.
+func NewSender() Sender {
+	switch strings.ToLower(utils.GetEnv("SMS_DRIVER", "log").(string)) {
+	case "file":
+		return &FileSender{Dir: utils.GetEnv("SMS_DIR", "tmp/sms").(string)}
+	default:
+		return &LogSender{ShowMessage: utils.GetEnv("SMS_LOG_MESSAGE", false).(bool)}
+	}
+}
+
+// LogSender logs the recipient of each SMS. The text is a verification code, so it is only logged
+// with ShowMessage, until a real SMS gateway is wired in.
+type LogSender struct {
+	ShowMessage bool
+}
+
+func (s *LogSender) Send(to, message string) error {
+	sink.Log{Name: "SMS", Reveal: s.ShowMessage}.Write("To: "+to, message)
+	return nil
+}
+
+// FileSender writes each SMS to a .txt file in Dir, one file per code sent.
+type FileSender struct {
+	Dir string
+}
+
+func (s *FileSender) Send(to, message string) error {
+	content := fmt.Sprintf("To: %s\nDate: %s\n\n%s\n", to, time.Now().Format(time.RFC1123Z), message)
+	return sink.File{Dir: s.Dir, Ext: ".txt"}.Write(content)
+}
@@ -29,6 +29,11 @@ func GetEnv(key string, def interface{}) interface{} {
 		} else {
 			return time.Minute //default
 		}
+	case float64:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+		break
 	case bool:
 		if b, err := strconv.ParseBool(v); err == nil {
 			return b
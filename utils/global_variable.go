@@ -13,7 +13,6 @@ const (
 
 const (
 	RoleAdmin    = "admin"
-	RoleMember   = "member"
 	RoleCustomer = "customer"
 	RoleMechanic = "mechanic"
 	RoleCashier  = "cashier"
@@ -28,4 +27,14 @@ const (
 	StsApproved   = "approved"
 	StsRejected   = "rejected"
 	StsOpen       = "open"
+
+	StsWaitingParts = "waiting_parts"
+	StsQualityCheck = "quality_check"
+
+	StsStarted = "started"
+	StsDone    = "done"
+	StsSkipped = "skipped"
+
+	StsPaid          = "paid"
+	StsPartiallyPaid = "partially_paid"
 )
@@ -2,39 +2,81 @@ package utils
 
 import (
 	"errors"
-	"fmt"
-	"os"
 	"strings"
+	"sync"
 	"time"
 	"workshop-management/internal/domain/user"
+	"workshop-management/pkg/jwks"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type AppClaims struct {
-	UserId   string `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserId    string `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	SessionId string `json:"sid,omitempty"`
 	*jwt.RegisteredClaims
 }
 
-func GenerateJwt(user *user.Users, logId string) (string, error) {
+// AccessTokenTTL is the lifetime of access tokens, JWT_ACCESS_TTL minutes (default 15).
+func AccessTokenTTL() time.Duration {
+	return time.Duration(GetEnv("JWT_ACCESS_TTL", 15).(int)) * time.Minute
+}
+
+// JwtKeys returns the keys tokens are signed and verified with, loaded once from the environment.
+// Without JWT_KEYS_DIR tokens are signed with the JWT_KEY shared secret (HS256). With it, the PEM keys
+// in that directory are used (RS256 or EdDSA, identified by kid), JWT_SIGNING_KID selects the signing
+// key, and JWT_KEY is still accepted for tokens without a kid so that switching does not log users out.
+var JwtKeys = sync.OnceValues(func() (*jwks.KeySet, error) {
+	secret := GetEnv("JWT_KEY", "").(string)
+
+	dir := GetEnv("JWT_KEYS_DIR", "").(string)
+	if dir == "" {
+		if secret == "" {
+			return nil, errors.New("JWT_KEY or JWT_KEYS_DIR must be set")
+		}
+		return jwks.NewHMAC([]byte(secret)), nil
+	}
+
+	keys, err := jwks.LoadDir(dir, GetEnv("JWT_SIGNING_KID", "").(string))
+	if err != nil {
+		return nil, err
+	}
+	if secret != "" {
+		keys.AddLegacyHMAC([]byte(secret))
+	}
+	return keys, nil
+})
+
+func GenerateJwt(user *user.Users, sessionId, logId string) (string, error) {
 	claims := AppClaims{
-		UserId:   user.Id,
-		Username: user.Name,
-		Role:     user.Role,
+		UserId:    user.Id,
+		Username:  user.Name,
+		Role:      user.Role,
+		SessionId: sessionId,
 		RegisteredClaims: &jwt.RegisteredClaims{
 			ID:        logId,
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(GetEnv("JWT_EXP", 24).(int)))),
+			Issuer:    GetEnv("JWT_ISSUER", "").(string),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL())),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
+	keys, err := JwtKeys()
+	if err != nil {
+		return "", err
+	}
+
 	//	generate auth
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims)
+	key := keys.SigningKey()
+	token := jwt.NewWithClaims(key.Method, &claims)
+	if key.Kid != "" {
+		token.Header["kid"] = key.Kid
+	}
 
-	signedToken, err := token.SignedString([]byte(os.Getenv("JWT_KEY")))
+	signedToken, err := token.SignedString(key.SignKey())
 	if err != nil {
 		return "", err
 	}
@@ -58,15 +100,19 @@ func JwtClaim(tokenString string) (map[string]interface{}, error) {
 		return nil, errors.New("empty token")
 	}
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		hmacSecretString := GetEnv("JWT_KEY", "").(string)
-		hmacSecret := []byte(hmacSecretString)
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return "", fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
+	keys, err := JwtKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods([]string{
+		jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg(),
+	})}
+	if issuer := GetEnv("JWT_ISSUER", "").(string); issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
 
-		return hmacSecret, nil
-	})
+	token, err := jwt.Parse(tokenString, keys.Keyfunc, options...)
 
 	if err != nil || !token.Valid {
 		return nil, err
@@ -9,3 +9,17 @@ func TitleCase(s string) string {
 	titleCaser := cases.Title(language.English)
 	return titleCaser.String(s)
 }
+
+// Unique returns values without duplicates, keeping the first occurrence of each.
+func Unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	ret := make([]string, 0, len(values))
+	for _, v := range values {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		ret = append(ret, v)
+	}
+	return ret
+}
@@ -37,6 +37,14 @@ func mapValidateMessage(fe validator.FieldError) string {
 		return "Should be less than " + fe.Param()
 	case "gtefield":
 		return "Should be greater than " + fe.Param()
+	case "gt":
+		return "Should be greater than " + fe.Param()
+	case "oneof":
+		return "Should be one of: " + fe.Param()
+	case "len":
+		return "Should be exactly " + fe.Param() + " characters"
+	case "numeric":
+		return "Should be numeric"
 	}
 
 	return "Invalid value"
@@ -59,17 +67,26 @@ func ValidateError(err error, reflectType reflect.Type, tagName string) []Valida
 }
 
 func ValidateUUID(ctx *gin.Context, logID uuid.UUID) (string, error) {
-	id := ctx.Param("id")
+	return validateUUID(ctx, logID, "id", "ID")
+}
+
+// ValidateUUIDParam is ValidateUUID for path parameters other than ":id".
+func ValidateUUIDParam(ctx *gin.Context, logID uuid.UUID, param string) (string, error) {
+	return validateUUID(ctx, logID, param, param)
+}
+
+func validateUUID(ctx *gin.Context, logID uuid.UUID, param, label string) (string, error) {
+	id := ctx.Param(param)
 	if id == "" {
 		res := response.Response(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), logID, nil)
-		res.Error = "ID parameter is required"
+		res.Error = fmt.Sprintf("%s parameter is required", label)
 		ctx.JSON(http.StatusBadRequest, res)
-		return "", fmt.Errorf("missing ID")
+		return "", fmt.Errorf("missing %s", label)
 	}
 
 	if _, err := uuid.Parse(id); err != nil {
 		res := response.Response(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), logID, nil)
-		res.Error = response.Errors{Code: http.StatusBadRequest, Message: "ID must be a valid UUID"}
+		res.Error = response.Errors{Code: http.StatusBadRequest, Message: fmt.Sprintf("%s must be a valid UUID", label)}
 		ctx.JSON(http.StatusBadRequest, res)
 		return "", fmt.Errorf("invalid UUID")
 	}